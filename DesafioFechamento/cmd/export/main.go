@@ -0,0 +1,46 @@
+package main
+
+import (
+	"app/internal"
+	"app/internal/application"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func main() {
+	// env
+	// - flags
+	dir := flag.String("dir", "docs/db/json", "directory where the customers, products, invoices and sales files are written")
+	format := flag.String("format", "json", "format of the files: json or csv")
+	flag.Parse()
+
+	// app
+	// - config
+	cfg := &application.ConfigApplicationExport{
+		Db: &mysql.Config{
+			User:   "root",
+			Passwd: "root",
+			Net:    "tcp",
+			Addr:   "localhost:3306",
+			DBName: "fantasy_products",
+		},
+		Dir:    *dir,
+		Format: internal.StorageFormat(*format),
+	}
+	app := application.NewApplicationExport(cfg)
+	// - set up
+	err := app.SetUp()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	// - run
+	err = app.Run()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
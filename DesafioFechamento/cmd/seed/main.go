@@ -0,0 +1,48 @@
+package main
+
+import (
+	"app/internal"
+	"app/internal/application"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func main() {
+	// env
+	// - flags
+	dir := flag.String("dir", "docs/db/json", "directory of the customers, products, invoices and sales files")
+	format := flag.String("format", "json", "format of the files: json or csv")
+	dryRun := flag.Bool("dry-run", false, "validate the files and report the summary without saving anything")
+	flag.Parse()
+
+	// app
+	// - config
+	cfg := &application.ConfigApplicationSeed{
+		Db: &mysql.Config{
+			User:   "root",
+			Passwd: "root",
+			Net:    "tcp",
+			Addr:   "localhost:3306",
+			DBName: "fantasy_products",
+		},
+		Dir:    *dir,
+		Format: internal.StorageFormat(*format),
+		DryRun: *dryRun,
+	}
+	app := application.NewApplicationSeed(cfg)
+	// - set up
+	err := app.SetUp()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	// - run
+	err = app.Run()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -69,26 +69,27 @@ func (a *ApplicationDefault) SetUp() (err error) {
 		return
 	}
 
-	// - storage
-	stCustomer := storage.NewCustomersStorage("docs/db/json/customers.json")
-	stProduct := storage.NewProductsStorage("docs/db/json/products.json")
-	stInvoice := storage.NewInvoicesStorage("docs/db/json/invoices.json")
-	stSale := storage.NewSalesStorage("docs/db/json/sales.json")
 	// - repository
-	rpCustomer := repository.NewCustomersMySQL(a.db, stCustomer)
-	rpProduct := repository.NewProductsMySQL(a.db, stProduct)
-	rpInvoice := repository.NewInvoicesMySQL(a.db, stInvoice)
-	rpSale := repository.NewSalesMySQL(a.db, stSale)
+	rpCustomer := repository.NewCustomersMySQL(a.db)
+	rpProduct := repository.NewProductsMySQL(a.db)
+	rpInvoice := repository.NewInvoicesMySQL(a.db)
+	rpSale := repository.NewSalesMySQL(a.db)
+	rpReport := repository.NewReportsMySQL(a.db)
+	rpExport := repository.NewExportMySQL(a.db)
 	// - service
 	svCustomer := service.NewCustomersDefault(rpCustomer)
 	svProduct := service.NewProductsDefault(rpProduct)
-	svInvoice := service.NewInvoicesDefault(rpInvoice)
-	svSale := service.NewSalesDefault(rpSale)
+	svInvoice := service.NewInvoicesDefault(rpInvoice, rpCustomer, rpProduct)
+	svSale := service.NewSalesDefault(rpSale, rpProduct, rpInvoice)
+	svReport := service.NewReportsDefault(rpReport)
+	svExport := service.NewExportDefault(rpExport)
 	// - handler
 	hdCustomer := handler.NewCustomersDefault(svCustomer)
 	hdProduct := handler.NewProductsDefault(svProduct)
 	hdInvoice := handler.NewInvoicesDefault(svInvoice)
 	hdSale := handler.NewSalesDefault(svSale)
+	hdReport := handler.NewReportsDefault(svReport)
+	hdExport := handler.NewExportDefault(svExport, storage.NewStorages, storage.StoragesFiles)
 
 	// routes
 	// - router
@@ -106,6 +107,16 @@ func (a *ApplicationDefault) SetUp() (err error) {
 		r.Get("/spent-more-money", hdCustomer.GetSpentMoreMoney())
 		// - POST /customers
 		r.Post("/", hdCustomer.Create())
+		// - GET /customers/{id}
+		r.Get("/{id}", hdCustomer.GetById())
+		// - GET /customers/{id}/invoices
+		r.Get("/{id}/invoices", hdCustomer.GetInvoices())
+		// - PUT /customers/{id}
+		r.Put("/{id}", hdCustomer.Update())
+		// - PATCH /customers/{id}
+		r.Patch("/{id}", hdCustomer.Patch())
+		// - DELETE /customers/{id}
+		r.Delete("/{id}", hdCustomer.Delete())
 	})
 	a.router.Route("/products", func(r chi.Router) {
 		// - GET /products
@@ -114,19 +125,55 @@ func (a *ApplicationDefault) SetUp() (err error) {
 		r.Get("/best-selling", hdProduct.GetBestSelling())
 		// - POST /products
 		r.Post("/", hdProduct.Create())
+		// - GET /products/{id}
+		r.Get("/{id}", hdProduct.GetById())
+		// - PUT /products/{id}
+		r.Put("/{id}", hdProduct.Update())
+		// - PATCH /products/{id}
+		r.Patch("/{id}", hdProduct.Patch())
+		// - DELETE /products/{id}
+		r.Delete("/{id}", hdProduct.Delete())
 	})
 	a.router.Route("/invoices", func(r chi.Router) {
 		// - GET /invoices
 		r.Get("/", hdInvoice.GetAll())
+		// - GET /invoices/{id}/discrepancy
+		r.Get("/{id}/discrepancy", hdInvoice.GetDiscrepancy())
 		// - POST /invoices
 		r.Post("/", hdInvoice.Create())
+		// - POST /invoices/checkout
+		r.Post("/checkout", hdInvoice.Checkout())
+		// - POST /invoices/reconcile
+		r.Post("/reconcile", hdInvoice.Reconcile())
+		// - GET /invoices/{id}
+		r.Get("/{id}", hdInvoice.GetById())
+		// - PUT /invoices/{id}
+		r.Put("/{id}", hdInvoice.Update())
+		// - PATCH /invoices/{id}
+		r.Patch("/{id}", hdInvoice.Patch())
+		// - DELETE /invoices/{id}
+		r.Delete("/{id}", hdInvoice.Delete())
 	})
 	a.router.Route("/sales", func(r chi.Router) {
 		// - GET /sales
 		r.Get("/", hdSale.GetAll())
 		// - POST /sales
 		r.Post("/", hdSale.Create())
+		// - GET /sales/{id}
+		r.Get("/{id}", hdSale.GetById())
+		// - PUT /sales/{id}
+		r.Put("/{id}", hdSale.Update())
+		// - PATCH /sales/{id}
+		r.Patch("/{id}", hdSale.Patch())
+		// - DELETE /sales/{id}
+		r.Delete("/{id}", hdSale.Delete())
 	})
+	a.router.Route("/reports", func(r chi.Router) {
+		// - GET /reports/sales
+		r.Get("/sales", hdReport.GetSales())
+	})
+	// - GET /export
+	a.router.Get("/export", hdExport.Export())
 
 	return
 }
@@ -0,0 +1,126 @@
+package application
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/storage"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ConfigApplicationExport is the configuration for NewApplicationExport.
+type ConfigApplicationExport struct {
+	// Db is the database configuration.
+	Db *mysql.Config
+	// Dir is the directory where the files are written.
+	Dir string
+	// Format is the format of the files.
+	Format internal.StorageFormat
+	// Out is where the summary is written.
+	Out io.Writer
+}
+
+// NewApplicationExport creates a new ApplicationExport.
+func NewApplicationExport(config *ConfigApplicationExport) *ApplicationExport {
+	// default values
+	defaultCfg := &ConfigApplicationExport{
+		Db:     nil,
+		Dir:    "docs/db/json",
+		Format: internal.StorageFormatJSON,
+		Out:    os.Stdout,
+	}
+	if config != nil {
+		if config.Db != nil {
+			defaultCfg.Db = config.Db
+		}
+		if config.Dir != "" {
+			defaultCfg.Dir = config.Dir
+		}
+		if config.Format != "" {
+			defaultCfg.Format = config.Format
+		}
+		if config.Out != nil {
+			defaultCfg.Out = config.Out
+		}
+	}
+
+	return &ApplicationExport{
+		cfgDb:     defaultCfg.Db,
+		cfgDir:    defaultCfg.Dir,
+		cfgFormat: defaultCfg.Format,
+		out:       defaultCfg.Out,
+	}
+}
+
+// ApplicationExport is an implementation of the Application interface that writes the database into files.
+type ApplicationExport struct {
+	// cfgDb is the database configuration.
+	cfgDb *mysql.Config
+	// cfgDir is the directory where the files are written.
+	cfgDir string
+	// cfgFormat is the format of the files.
+	cfgFormat internal.StorageFormat
+	// out is where the summary is written.
+	out io.Writer
+	// db is the database connection.
+	db *sql.DB
+	// st are the storages the data is written into.
+	st internal.Storages
+	// sv is the export service.
+	sv internal.ServiceExport
+}
+
+// SetUp sets up the application.
+func (a *ApplicationExport) SetUp() (err error) {
+	// dependencies
+	// - db: init
+	a.db, err = sql.Open("mysql", a.cfgDb.FormatDSN())
+	if err != nil {
+		return
+	}
+	// - db: ping
+	err = a.db.Ping()
+	if err != nil {
+		return
+	}
+
+	// - storage
+	err = os.MkdirAll(a.cfgDir, 0755)
+	if err != nil {
+		return
+	}
+	a.st, err = storage.NewStorages(a.cfgDir, a.cfgFormat)
+	if err != nil {
+		return
+	}
+	// - repository
+	rp := repository.NewExportMySQL(a.db)
+	// - service
+	a.sv = service.NewExportDefault(rp)
+
+	return
+}
+
+// Run runs the application.
+func (a *ApplicationExport) Run() (err error) {
+	defer a.db.Close()
+
+	s, err := a.sv.Export(a.st)
+	if err != nil {
+		return
+	}
+
+	// summary
+	fmt.Fprintf(a.out, "exported to %s (%s)\n", a.cfgDir, a.cfgFormat)
+	fmt.Fprintf(a.out, "%-10s %9d\n", "customers", s.Customers)
+	fmt.Fprintf(a.out, "%-10s %9d\n", "products", s.Products)
+	fmt.Fprintf(a.out, "%-10s %9d\n", "invoices", s.Invoices)
+	fmt.Fprintf(a.out, "%-10s %9d\n", "sales", s.Sales)
+	return
+}
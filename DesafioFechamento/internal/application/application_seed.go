@@ -0,0 +1,145 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/storage"
+	"database/sql"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ConfigApplicationSeed is the configuration for NewApplicationSeed.
+type ConfigApplicationSeed struct {
+	// Db is the database configuration.
+	Db *mysql.Config
+	// Dir is the directory of the files.
+	Dir string
+	// Format is the format of the files.
+	Format internal.StorageFormat
+	// DryRun validates and computes the summary without saving anything.
+	DryRun bool
+	// Out is where the summary is written.
+	Out io.Writer
+}
+
+// NewApplicationSeed creates a new ApplicationSeed.
+func NewApplicationSeed(config *ConfigApplicationSeed) *ApplicationSeed {
+	// default values
+	defaultCfg := &ConfigApplicationSeed{
+		Db:     nil,
+		Dir:    "docs/db/json",
+		Format: internal.StorageFormatJSON,
+		Out:    os.Stdout,
+	}
+	if config != nil {
+		if config.Db != nil {
+			defaultCfg.Db = config.Db
+		}
+		if config.Dir != "" {
+			defaultCfg.Dir = config.Dir
+		}
+		if config.Format != "" {
+			defaultCfg.Format = config.Format
+		}
+		if config.Out != nil {
+			defaultCfg.Out = config.Out
+		}
+		defaultCfg.DryRun = config.DryRun
+	}
+
+	return &ApplicationSeed{
+		cfgDb:     defaultCfg.Db,
+		cfgDir:    defaultCfg.Dir,
+		cfgFormat: defaultCfg.Format,
+		cfgDryRun: defaultCfg.DryRun,
+		out:       defaultCfg.Out,
+	}
+}
+
+// ApplicationSeed is an implementation of the Application interface that loads the files into the database.
+type ApplicationSeed struct {
+	// cfgDb is the database configuration.
+	cfgDb *mysql.Config
+	// cfgDir is the directory of the files.
+	cfgDir string
+	// cfgFormat is the format of the files.
+	cfgFormat internal.StorageFormat
+	// cfgDryRun validates and computes the summary without saving anything.
+	cfgDryRun bool
+	// out is where the summary is written.
+	out io.Writer
+	// db is the database connection.
+	db *sql.DB
+	// sv is the seed service.
+	sv internal.ServiceSeed
+}
+
+// SetUp sets up the application.
+func (a *ApplicationSeed) SetUp() (err error) {
+	// dependencies
+	// - db: init
+	a.db, err = sql.Open("mysql", a.cfgDb.FormatDSN())
+	if err != nil {
+		return
+	}
+	// - db: ping
+	err = a.db.Ping()
+	if err != nil {
+		return
+	}
+
+	// - storage
+	st, err := storage.NewStorages(a.cfgDir, a.cfgFormat)
+	if err != nil {
+		return
+	}
+	// - repository
+	rp := repository.NewSeedMySQL(a.db)
+	// - service
+	a.sv = service.NewSeedDefault(rp, st.Customer, st.Product, st.Invoice, st.Sale)
+
+	return
+}
+
+// Run runs the application.
+func (a *ApplicationSeed) Run() (err error) {
+	defer a.db.Close()
+
+	s, err := a.sv.Seed(a.cfgDryRun)
+	if err != nil {
+		// list every invalid field
+		var fe *internal.FieldsError
+		if errors.As(err, &fe) {
+			for _, f := range fe.Fields {
+				fmt.Fprintf(a.out, "  - %s: %s\n", f.Field, f.Message)
+			}
+			err = fe.Err
+		}
+		return
+	}
+
+	// summary
+	if a.cfgDryRun {
+		fmt.Fprintln(a.out, "dry run: nothing was saved")
+	}
+	fmt.Fprintf(a.out, "%-10s %9s %9s %9s\n", "entity", "inserted", "updated", "unchanged")
+	for _, e := range []struct {
+		name  string
+		count internal.SeedCount
+	}{
+		{"customers", s.Customers},
+		{"products", s.Products},
+		{"invoices", s.Invoices},
+		{"sales", s.Sales},
+	} {
+		fmt.Fprintf(a.out, "%-10s %9d %9d %9d\n", e.name, e.count.Inserted, e.count.Updated, e.count.Unchanged)
+	}
+	return
+}
@@ -1,5 +1,7 @@
 package internal
 
+import "time"
+
 // CustomerAttributes is the struct that represents the attributes of a customer.
 type CustomerAttributes struct {
 	// FirstName is the first name of the customer.
@@ -18,6 +20,29 @@ type Customer struct {
 	CustomerAttributes
 }
 
+// CustomerPatch is the struct that represents the fields of a customer to be patched.
+// A nil field is left untouched.
+type CustomerPatch struct {
+	// FirstName is the first name of the customer.
+	FirstName *string
+	// LastName is the last name of the customer.
+	LastName *string
+	// Condition is the condition of the customer.
+	Condition *int
+}
+
+// CustomerReportFilter is the struct that represents the filters of the customer reports.
+type CustomerReportFilter struct {
+	// From is the lower bound of the invoices datetime, inclusive. Zero means no lower bound.
+	From time.Time
+	// To is the upper bound of the invoices datetime, inclusive. Zero means no upper bound.
+	To time.Time
+	// Limit is the maximum number of rows of the report. Zero means no limit.
+	Limit int
+	// Condition is the condition of the customers. Nil means any condition.
+	Condition *int
+}
+
 type CustomerTotalValue struct {
 	Condition  int
 	TotalValue float64
@@ -28,3 +53,45 @@ type CustomerSpentMoreMoney struct {
 	LastName  string
 	Amount    float64
 }
+
+// CustomerPurchaseLine is the struct that represents a sale of a customer's invoice.
+type CustomerPurchaseLine struct {
+	// SaleId is the id of the sale.
+	SaleId int
+	// ProductId is the id of the product sold.
+	ProductId int
+	// Description is the description of the product sold.
+	Description string
+	// UnitPrice is the price of the product sold.
+	UnitPrice float64
+	// Quantity is the quantity of the product sold.
+	Quantity int
+	// Total is the quantity times the unit price.
+	Total float64
+}
+
+// CustomerPurchase is the struct that represents an invoice of a customer with its sales.
+type CustomerPurchase struct {
+	// Invoice is the invoice.
+	Invoice
+	// Lines are the sales of the invoice.
+	Lines []CustomerPurchaseLine
+}
+
+// CustomerPurchaseHistory is the struct that represents every purchase of a customer.
+type CustomerPurchaseHistory struct {
+	// Customer is the customer.
+	Customer
+	// Purchases are the invoices of the customer, oldest first.
+	Purchases []CustomerPurchase
+	// Invoices is the number of invoices of the customer.
+	Invoices int
+	// Units is the quantity of products bought by the customer.
+	Units int
+	// Total is the sum of the totals of every sale of the customer.
+	Total float64
+	// FirstPurchase is the datetime of the oldest invoice. Empty if there are no invoices.
+	FirstPurchase string
+	// LastPurchase is the datetime of the newest invoice. Empty if there are no invoices.
+	LastPurchase string
+}
@@ -1,13 +1,29 @@
 package internal
 
+import "errors"
+
+var (
+	// ErrRepositoryCustomerNotFound is returned when a customer is not found.
+	ErrRepositoryCustomerNotFound = errors.New("repository: customer not found")
+)
+
 // RepositoryCustomer is the interface that wraps the basic methods that a customer repository should implement.
 type RepositoryCustomer interface {
 	// FindAll returns all customers saved in the database.
 	FindAll() (c []Customer, err error)
+	// FindById returns a customer by its id.
+	FindById(id int) (c Customer, err error)
 
-	GetTotalValues() (totalValues []CustomerTotalValue, err error)
-
-	GetSpentMoreMoney() (spentMoreMoney []CustomerSpentMoreMoney, err error)
+	// GetTotalValues returns the total spent by condition of the customers, filtered by f.
+	GetTotalValues(f CustomerReportFilter) (totalValues []CustomerTotalValue, err error)
+	// GetSpentMoreMoney returns the customers that spent more money, filtered by f.
+	GetSpentMoreMoney(f CustomerReportFilter) (spentMoreMoney []CustomerSpentMoreMoney, err error)
+	// GetPurchases returns the invoices of a customer with their sales, oldest first.
+	GetPurchases(id int) (p []CustomerPurchase, err error)
 	// Save saves a customer into the database.
 	Save(c *Customer) (err error)
+	// Update updates a customer in the database.
+	Update(c *Customer) (err error)
+	// Delete deletes a customer from the database, along with its invoices and sales.
+	Delete(id int) (err error)
 }
@@ -4,10 +4,21 @@ package internal
 type ServiceCustomer interface {
 	// FindAll returns all customers
 	FindAll() (c []Customer, err error)
+	// FindById returns a customer by its id
+	FindById(id int) (c Customer, err error)
 
-	GetTotalValues() (totalValues []CustomerTotalValue, err error)
-
-	GetSpentMoreMoney() (spentMoreMoney []CustomerSpentMoreMoney, err error)
+	// GetTotalValues returns the total spent by condition of the customers, filtered by f.
+	GetTotalValues(f CustomerReportFilter) (totalValues []CustomerTotalValue, err error)
+	// GetSpentMoreMoney returns the customers that spent more money, filtered by f.
+	GetSpentMoreMoney(f CustomerReportFilter) (spentMoreMoney []CustomerSpentMoreMoney, err error)
+	// GetPurchaseHistory returns the invoices of a customer with their sales and a summary
+	GetPurchaseHistory(id int) (h CustomerPurchaseHistory, err error)
 	// Save saves a customer
 	Save(c *Customer) (err error)
+	// Update updates a customer
+	Update(c *Customer) (err error)
+	// Patch updates the given fields of a customer
+	Patch(id int, p CustomerPatch) (c Customer, err error)
+	// Delete deletes a customer
+	Delete(id int) (err error)
 }
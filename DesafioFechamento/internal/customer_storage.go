@@ -4,4 +4,6 @@ package internal
 type StorageCustomer interface {
 	// FindAll returns all customers
 	FindAll() (c []Customer, err error)
+	// SaveAll replaces the stored customers with c.
+	SaveAll(c []Customer) (err error)
 }
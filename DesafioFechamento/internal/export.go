@@ -0,0 +1,42 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrStorageFormatInvalid is returned when the format of the files is not supported.
+	ErrStorageFormatInvalid = errors.New("storage: invalid format")
+)
+
+// StorageFormat is the format of the files of the storages.
+type StorageFormat string
+
+const (
+	// StorageFormatJSON stores every entity in a json array, the format read by the seed.
+	StorageFormatJSON StorageFormat = "json"
+	// StorageFormatCSV stores every entity in a csv file with a header.
+	StorageFormatCSV StorageFormat = "csv"
+)
+
+// Storages is the struct that groups the storage of every entity.
+type Storages struct {
+	// Customer is the storage for customers.
+	Customer StorageCustomer
+	// Product is the storage for products.
+	Product StorageProduct
+	// Invoice is the storage for invoices.
+	Invoice StorageInvoice
+	// Sale is the storage for sales.
+	Sale StorageSale
+}
+
+// ExportSummary is the struct that represents the number of rows exported of each entity.
+type ExportSummary struct {
+	// Customers is the number of customers exported.
+	Customers int
+	// Products is the number of products exported.
+	Products int
+	// Invoices is the number of invoices exported.
+	Invoices int
+	// Sales is the number of sales exported.
+	Sales int
+}
@@ -0,0 +1,8 @@
+package internal
+
+// RepositoryExport is the interface that wraps the basic methods that an export repository should implement.
+type RepositoryExport interface {
+	// Snapshot returns every customer, product, invoice and sale of the database as of the same moment,
+	// so the references between them are consistent.
+	Snapshot() (d SeedData, err error)
+}
@@ -0,0 +1,7 @@
+package internal
+
+// ServiceExport is the interface that wraps the basic methods that an export service should implement.
+type ServiceExport interface {
+	// Export saves every customer, product, invoice and sale of the database into the storages.
+	Export(st Storages) (s ExportSummary, err error)
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrServiceInvalidField is returned when a field has an invalid value.
+	ErrServiceInvalidField = errors.New("service: invalid field")
+	// ErrServiceReferenceNotFound is returned when a field references an entity that does not exist.
+	ErrServiceReferenceNotFound = errors.New("service: referenced entity not found")
+)
+
+// FieldError is the struct that represents a failure of a single field.
+type FieldError struct {
+	// Field is the name of the field.
+	Field string
+	// Message is the description of the failure.
+	Message string
+}
+
+// FieldsError is the error returned by the services when one or more fields fail.
+// It wraps ErrServiceInvalidField or ErrServiceReferenceNotFound.
+type FieldsError struct {
+	// Err is the kind of the failure.
+	Err error
+	// Fields are the fields that failed.
+	Fields []FieldError
+}
+
+// Error returns the message of the error.
+func (e *FieldsError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for ix, f := range e.Fields {
+		msgs[ix] = f.Field + " " + f.Message
+	}
+	return e.Err.Error() + ": " + strings.Join(msgs, ", ")
+}
+
+// Unwrap returns the kind of the failure.
+func (e *FieldsError) Unwrap() error {
+	return e.Err
+}
@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"app/internal"
 
 	"github.com/bootcamp-go/web/request"
 	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
 )
 
 // NewCustomersDefault returns a new CustomersDefault
@@ -40,6 +43,38 @@ type SpentMoreMoneyJSON struct {
 	Amount    float64 `json:"amount"`
 }
 
+// PurchaseLineJSON is a struct that represents a sale of a customer's invoice in JSON format
+type PurchaseLineJSON struct {
+	SaleId      int     `json:"sale_id"`
+	ProductId   int     `json:"product_id"`
+	Description string  `json:"description"`
+	UnitPrice   float64 `json:"unit_price"`
+	Quantity    int     `json:"quantity"`
+	Total       float64 `json:"line_total"`
+}
+
+// PurchaseJSON is a struct that represents an invoice of a customer with its sales in JSON format
+type PurchaseJSON struct {
+	InvoiceJSON
+	Lines []PurchaseLineJSON `json:"lines"`
+}
+
+// PurchaseSummaryJSON is a struct that represents the lifetime summary of a customer in JSON format
+type PurchaseSummaryJSON struct {
+	Invoices      int     `json:"invoices"`
+	Units         int     `json:"units"`
+	Total         float64 `json:"total"`
+	FirstPurchase string  `json:"first_purchase,omitempty"`
+	LastPurchase  string  `json:"last_purchase,omitempty"`
+}
+
+// PurchaseHistoryJSON is a struct that represents the purchase history of a customer in JSON format
+type PurchaseHistoryJSON struct {
+	Customer CustomerJSON        `json:"customer"`
+	Invoices []PurchaseJSON      `json:"invoices"`
+	Summary  PurchaseSummaryJSON `json:"summary"`
+}
+
 // GetAll returns all customers
 func (h *CustomersDefault) GetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -72,9 +107,168 @@ func (h *CustomersDefault) GetAll() http.HandlerFunc {
 	}
 }
 
+// GetById returns a customer by its id
+func (h *CustomersDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		c, err := h.sv.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryCustomerNotFound):
+				response.Error(w, http.StatusNotFound, "customer not found")
+			default:
+				log.Println(err)
+				response.Error(w, http.StatusInternalServerError, "error getting customer")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "customer found",
+			"data": CustomerJSON{
+				Id:        c.Id,
+				FirstName: c.FirstName,
+				LastName:  c.LastName,
+				Condition: c.Condition,
+			},
+		})
+	}
+}
+
+// ErrQueryConditionInvalid is returned when the query parameter condition is invalid.
+var ErrQueryConditionInvalid = errors.New("invalid condition parameter, expected 0, 1 or all")
+
+// GetInvoices returns the invoices of a customer with their sales and a lifetime summary
+func (h *CustomersDefault) GetInvoices() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		hs, err := h.sv.GetPurchaseHistory(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryCustomerNotFound):
+				response.Error(w, http.StatusNotFound, "customer not found")
+			default:
+				log.Println(err)
+				response.Error(w, http.StatusInternalServerError, "error getting customer invoices")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		data := PurchaseHistoryJSON{
+			Customer: CustomerJSON{
+				Id:        hs.Id,
+				FirstName: hs.FirstName,
+				LastName:  hs.LastName,
+				Condition: hs.Condition,
+			},
+			Invoices: make([]PurchaseJSON, len(hs.Purchases)),
+			Summary: PurchaseSummaryJSON{
+				Invoices:      hs.Invoices,
+				Units:         hs.Units,
+				Total:         hs.Total,
+				FirstPurchase: hs.FirstPurchase,
+				LastPurchase:  hs.LastPurchase,
+			},
+		}
+		for i, p := range hs.Purchases {
+			lines := make([]PurchaseLineJSON, len(p.Lines))
+			for j, l := range p.Lines {
+				lines[j] = PurchaseLineJSON{
+					SaleId:      l.SaleId,
+					ProductId:   l.ProductId,
+					Description: l.Description,
+					UnitPrice:   l.UnitPrice,
+					Quantity:    l.Quantity,
+					Total:       l.Total,
+				}
+			}
+			data.Invoices[i] = PurchaseJSON{
+				InvoiceJSON: InvoiceJSON{
+					Id:         p.Id,
+					Datetime:   p.Datetime,
+					Total:      p.Total,
+					CustomerId: p.CustomerId,
+				},
+				Lines: lines,
+			}
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "customer invoices found",
+			"data":    data,
+		})
+	}
+}
+
+// customerReportFilter parses the query parameters from, to, limit and condition of the customer reports.
+// Missing parameters take the values of def.
+func customerReportFilter(r *http.Request, def internal.CustomerReportFilter) (f internal.CustomerReportFilter, err error) {
+	f = def
+	// - from / to
+	from, to, err := queryDateRange(r)
+	if err != nil {
+		return
+	}
+	if !from.IsZero() {
+		f.From = from
+	}
+	if !to.IsZero() {
+		f.To = to
+	}
+	// - limit
+	f.Limit, err = queryLimit(r, def.Limit)
+	if err != nil {
+		return
+	}
+	// - condition
+	switch v := r.URL.Query().Get("condition"); v {
+	case "":
+	case "all":
+		f.Condition = nil
+	default:
+		var condition int
+		condition, err = strconv.Atoi(v)
+		if err != nil || (condition != 0 && condition != 1) {
+			err = ErrQueryConditionInvalid
+			return
+		}
+		f.Condition = &condition
+	}
+	return
+}
+
+// GetTotalValues returns the total spent by condition of the customers
 func (h *CustomersDefault) GetTotalValues() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		totalValues, err := h.sv.GetTotalValues()
+		// request
+		// - query parameters: from, to, limit, condition (default: all time, no limit, any condition)
+		f, err := customerReportFilter(r, internal.CustomerReportFilter{})
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// process
+		totalValues, err := h.sv.GetTotalValues(f)
 		if err != nil {
 			log.Println(err)
 			response.Error(w, http.StatusInternalServerError, "error getting total values")
@@ -95,9 +289,20 @@ func (h *CustomersDefault) GetTotalValues() http.HandlerFunc {
 	}
 }
 
+// GetSpentMoreMoney returns the customers that spent more money
 func (h *CustomersDefault) GetSpentMoreMoney() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		spentMoreMoney, err := h.sv.GetSpentMoreMoney()
+		// request
+		// - query parameters: from, to, limit, condition (default: all time, top 5, condition 1)
+		condition := 1
+		f, err := customerReportFilter(r, internal.CustomerReportFilter{Limit: 5, Condition: &condition})
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// process
+		spentMoreMoney, err := h.sv.GetSpentMoreMoney(f)
 		if err != nil {
 			log.Println(err)
 			response.Error(w, http.StatusInternalServerError, "error getting spent more money")
@@ -168,3 +373,143 @@ func (h *CustomersDefault) Create() http.HandlerFunc {
 		})
 	}
 }
+
+// Update replaces a customer
+func (h *CustomersDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodyCustomer
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error deserializing request body")
+			return
+		}
+
+		// process
+		// - deserialize
+		c := internal.Customer{
+			Id: id,
+			CustomerAttributes: internal.CustomerAttributes{
+				FirstName: reqBody.FirstName,
+				LastName:  reqBody.LastName,
+				Condition: reqBody.Condition,
+			},
+		}
+		// - update
+		err = h.sv.Update(&c)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryCustomerNotFound):
+				response.Error(w, http.StatusNotFound, "customer not found")
+			default:
+				log.Println(err)
+				response.Error(w, http.StatusInternalServerError, "error updating customer")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "customer updated",
+			"data": CustomerJSON{
+				Id:        c.Id,
+				FirstName: c.FirstName,
+				LastName:  c.LastName,
+				Condition: c.Condition,
+			},
+		})
+	}
+}
+
+// RequestBodyCustomerPatch is a struct that represents the request body for patching a customer
+type RequestBodyCustomerPatch struct {
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+	Condition *int    `json:"condition"`
+}
+
+// Patch updates the given fields of a customer
+func (h *CustomersDefault) Patch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodyCustomerPatch
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error deserializing request body")
+			return
+		}
+
+		// process
+		c, err := h.sv.Patch(id, internal.CustomerPatch{
+			FirstName: reqBody.FirstName,
+			LastName:  reqBody.LastName,
+			Condition: reqBody.Condition,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryCustomerNotFound):
+				response.Error(w, http.StatusNotFound, "customer not found")
+			default:
+				log.Println(err)
+				response.Error(w, http.StatusInternalServerError, "error updating customer")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "customer updated",
+			"data": CustomerJSON{
+				Id:        c.Id,
+				FirstName: c.FirstName,
+				LastName:  c.LastName,
+				Condition: c.Condition,
+			},
+		})
+	}
+}
+
+// Delete deletes a customer along with its invoices and sales
+func (h *CustomersDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		err = h.sv.Delete(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryCustomerNotFound):
+				response.Error(w, http.StatusNotFound, "customer not found")
+			default:
+				log.Println(err)
+				response.Error(w, http.StatusInternalServerError, "error deleting customer")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"app/internal"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// FieldErrorJSON is a struct that represents a field error in JSON format
+type FieldErrorJSON struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// responseFieldsError writes the response for an internal.FieldsError:
+// 409 when a referenced entity does not exist and 422 when a field is invalid
+func responseFieldsError(w http.ResponseWriter, err error) {
+	var fe *internal.FieldsError
+	if !errors.As(err, &fe) {
+		response.Error(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	code := http.StatusUnprocessableEntity
+	message := "invalid fields"
+	if errors.Is(fe, internal.ErrServiceReferenceNotFound) {
+		code = http.StatusConflict
+		message = "referenced entities not found"
+	}
+
+	fields := make([]FieldErrorJSON, len(fe.Fields))
+	for ix, f := range fe.Fields {
+		fields[ix] = FieldErrorJSON{
+			Field: f.Field,
+			Error: f.Message,
+		}
+	}
+	response.JSON(w, code, map[string]any{
+		"status":  http.StatusText(code),
+		"message": message,
+		"errors":  fields,
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"app/internal"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// NewExportDefault returns a new ExportDefault
+func NewExportDefault(sv internal.ServiceExport, newStorages func(dir string, format internal.StorageFormat) (internal.Storages, error), files []string) *ExportDefault {
+	return &ExportDefault{sv: sv, newStorages: newStorages, files: files}
+}
+
+// ExportDefault is a struct that returns the export handlers
+type ExportDefault struct {
+	// sv is the export's service
+	sv internal.ServiceExport
+	// newStorages returns the storages writing the files of a format in a directory
+	newStorages func(dir string, format internal.StorageFormat) (internal.Storages, error)
+	// files are the names of the files written by the storages, without the extension
+	files []string
+}
+
+// Export returns a zip archive with a file per entity in the format read by the seed
+func (h *ExportDefault) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: format
+		format := internal.StorageFormatJSON
+		if v := r.URL.Query().Get("format"); v != "" {
+			format = internal.StorageFormat(v)
+		}
+
+		// process
+		// - write the files in a temporary directory
+		dir, err := os.MkdirTemp("", "export")
+		if err != nil {
+			log.Println(err)
+			response.Error(w, http.StatusInternalServerError, "error exporting data")
+			return
+		}
+		defer os.RemoveAll(dir)
+
+		st, err := h.newStorages(dir, format)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrStorageFormatInvalid):
+				response.Error(w, http.StatusBadRequest, "invalid format, must be json or csv")
+			default:
+				log.Println(err)
+				response.Error(w, http.StatusInternalServerError, "error exporting data")
+			}
+			return
+		}
+		_, err = h.sv.Export(st)
+		if err != nil {
+			log.Println(err)
+			response.Error(w, http.StatusInternalServerError, "error exporting data")
+			return
+		}
+
+		// - archive the files
+		var buf bytes.Buffer
+		err = zipFiles(&buf, dir, h.files, string(format))
+		if err != nil {
+			log.Println(err)
+			response.Error(w, http.StatusInternalServerError, "error exporting data")
+			return
+		}
+
+		// response
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"export_%s.zip\"", format))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
+
+// zipFiles writes a zip archive into w with the files name.ext of dir.
+func zipFiles(w io.Writer, dir string, names []string, ext string) (err error) {
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		var src *os.File
+		src, err = os.Open(filepath.Join(dir, name+"."+ext))
+		if err != nil {
+			return
+		}
+		var dst io.Writer
+		dst, err = zw.Create(name + "." + ext)
+		if err == nil {
+			_, err = io.Copy(dst, src)
+		}
+		src.Close()
+		if err != nil {
+			return
+		}
+	}
+	err = zw.Close()
+	return
+}
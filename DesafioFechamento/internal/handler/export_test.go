@@ -0,0 +1,88 @@
+package handler_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/storage"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serviceExportStub is a ServiceExport saving fixed customers and nothing else.
+type serviceExportStub struct {
+	err error
+}
+
+func (s *serviceExportStub) Export(st internal.Storages) (internal.ExportSummary, error) {
+	if s.err != nil {
+		return internal.ExportSummary{}, s.err
+	}
+	err := st.Customer.SaveAll([]internal.Customer{{Id: 1, CustomerAttributes: internal.CustomerAttributes{FirstName: "John", LastName: "Doe", Condition: 1}}})
+	if err == nil {
+		err = st.Product.SaveAll(nil)
+	}
+	if err == nil {
+		err = st.Invoice.SaveAll(nil)
+	}
+	if err == nil {
+		err = st.Sale.SaveAll(nil)
+	}
+	return internal.ExportSummary{Customers: 1}, err
+}
+
+func TestExportDefault_Export(t *testing.T) {
+	t.Run("success - csv files archived", func(t *testing.T) {
+		hd := handler.NewExportDefault(&serviceExportStub{}, storage.NewStorages, storage.StoragesFiles)
+		req := httptest.NewRequest(http.MethodGet, "/export?format=csv", nil)
+		res := httptest.NewRecorder()
+
+		hd.Export()(res, req)
+
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "application/zip", res.Header().Get("Content-Type"))
+		require.Equal(t, "attachment; filename=\"export_csv.zip\"", res.Header().Get("Content-Disposition"))
+		zr, err := zip.NewReader(bytes.NewReader(res.Body.Bytes()), int64(res.Body.Len()))
+		require.NoError(t, err)
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		require.Equal(t, []string{"customers.csv", "products.csv", "invoices.csv", "sales.csv"}, names)
+		rc, err := zr.File[0].Open()
+		require.NoError(t, err)
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.Equal(t, "id,first_name,last_name,condition\n1,John,Doe,1\n", string(b))
+	})
+
+	t.Run("error - invalid format", func(t *testing.T) {
+		hd := handler.NewExportDefault(&serviceExportStub{}, storage.NewStorages, storage.StoragesFiles)
+		req := httptest.NewRequest(http.MethodGet, "/export?format=xml", nil)
+		res := httptest.NewRecorder()
+
+		hd.Export()(res, req)
+
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.JSONEq(t, `{"status":"Bad Request","message":"invalid format, must be json or csv"}`, res.Body.String())
+	})
+
+	t.Run("error - export fails", func(t *testing.T) {
+		hd := handler.NewExportDefault(&serviceExportStub{err: errors.New("connection lost")}, storage.NewStorages, storage.StoragesFiles)
+		req := httptest.NewRequest(http.MethodGet, "/export", nil)
+		res := httptest.NewRecorder()
+
+		hd.Export()(res, req)
+
+		require.Equal(t, http.StatusInternalServerError, res.Code)
+		require.JSONEq(t, `{"status":"Internal Server Error","message":"error exporting data"}`, res.Body.String())
+	})
+}
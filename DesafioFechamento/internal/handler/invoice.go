@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"app/internal"
 
 	"github.com/bootcamp-go/web/request"
 	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
 )
 
 // NewInvoicesDefault returns a new InvoicesDefault
@@ -58,11 +62,134 @@ func (h *InvoicesDefault) GetAll() http.HandlerFunc {
 	}
 }
 
+// GetById returns an invoice by its id
+func (h *InvoicesDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		i, err := h.sv.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryInvoiceNotFound):
+				response.Error(w, http.StatusNotFound, "invoice not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error getting invoice")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "invoice found",
+			"data": InvoiceJSON{
+				Id:         i.Id,
+				Datetime:   i.Datetime,
+				Total:      i.Total,
+				CustomerId: i.CustomerId,
+			},
+		})
+	}
+}
+
+// DiscrepancyJSON is a struct that represents an invoice total discrepancy in JSON format
+type DiscrepancyJSON struct {
+	InvoiceId     int     `json:"invoice_id"`
+	StoredTotal   float64 `json:"stored_total"`
+	ComputedTotal float64 `json:"computed_total"`
+	Difference    float64 `json:"difference"`
+}
+
+// Reconcile recomputes the invoices totals from their sales and returns the ones that drift.
+// The computed totals are written back when the query parameter apply is true.
+func (h *InvoicesDefault) Reconcile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: apply
+		var apply bool
+		if v := r.URL.Query().Get("apply"); v != "" {
+			var err error
+			apply, err = strconv.ParseBool(v)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "invalid apply parameter")
+				return
+			}
+		}
+
+		// process
+		d, err := h.sv.Reconcile(apply)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "error reconciling invoices")
+			return
+		}
+
+		// response
+		// - serialize
+		dJSON := make([]DiscrepancyJSON, len(d))
+		for ix, v := range d {
+			dJSON[ix] = DiscrepancyJSON{
+				InvoiceId:     v.InvoiceId,
+				StoredTotal:   v.StoredTotal,
+				ComputedTotal: v.ComputedTotal,
+				Difference:    v.Difference,
+			}
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "invoices reconciled",
+			"applied": apply,
+			"data":    dJSON,
+		})
+	}
+}
+
+// GetDiscrepancy returns the stored and computed totals of an invoice
+func (h *InvoicesDefault) GetDiscrepancy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		d, err := h.sv.GetDiscrepancy(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryInvoiceNotFound):
+				response.Error(w, http.StatusNotFound, "invoice not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error getting invoice discrepancy")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "invoice discrepancy found",
+			"data": DiscrepancyJSON{
+				InvoiceId:     d.InvoiceId,
+				StoredTotal:   d.StoredTotal,
+				ComputedTotal: d.ComputedTotal,
+				Difference:    d.Difference,
+			},
+		})
+	}
+}
+
 // RequestBodyInvoice is a struct that represents the request body for a invoice
 type RequestBodyInvoice struct {
-	Datetime   string  `json:"datetime"`
-	Total      float64 `json:"total"`
-	CustomerId int     `json:"customer_id"`
+	Datetime   string `json:"datetime"`
+	CustomerId int    `json:"customer_id"`
 }
 // Create creates a new invoice
 func (h *InvoicesDefault) Create() http.HandlerFunc {
@@ -81,14 +208,18 @@ func (h *InvoicesDefault) Create() http.HandlerFunc {
 		i := internal.Invoice{
 			InvoiceAttributes: internal.InvoiceAttributes{
 				Datetime:   reqBody.Datetime,
-				Total:      reqBody.Total,
 				CustomerId: reqBody.CustomerId,
 			},
 		}
 		// - save
 		err = h.sv.Save(&i)
 		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "error saving invoice")
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error saving invoice")
+			}
 			return
 		}
 
@@ -106,3 +237,242 @@ func (h *InvoicesDefault) Create() http.HandlerFunc {
 		})
 	}
 }
+
+// RequestBodyInvoiceCheckoutLine is a struct that represents a product line of the checkout request body
+type RequestBodyInvoiceCheckoutLine struct {
+	ProductId int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// RequestBodyInvoiceCheckout is a struct that represents the request body for an invoice checkout
+type RequestBodyInvoiceCheckout struct {
+	Datetime   string                           `json:"datetime"`
+	CustomerId int                              `json:"customer_id"`
+	Products   []RequestBodyInvoiceCheckoutLine `json:"products"`
+}
+
+// InvoiceCheckoutJSON is a struct that represents an invoice with its sales in JSON format
+type InvoiceCheckoutJSON struct {
+	InvoiceJSON
+	Sales []SaleJSON `json:"sales"`
+}
+
+// Checkout creates a new invoice together with its sales
+func (h *InvoicesDefault) Checkout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - body
+		var reqBody RequestBodyInvoiceCheckout
+		err := request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error parsing request body")
+			return
+		}
+
+		// process
+		// - deserialize
+		i := internal.Invoice{
+			InvoiceAttributes: internal.InvoiceAttributes{
+				Datetime:   reqBody.Datetime,
+				CustomerId: reqBody.CustomerId,
+			},
+		}
+		if i.Datetime == "" {
+			i.Datetime = time.Now().Format(time.DateTime)
+		}
+		s := make([]internal.Sale, len(reqBody.Products))
+		for ix, v := range reqBody.Products {
+			s[ix] = internal.Sale{
+				SaleAttributes: internal.SaleAttributes{
+					Quantity:  v.Quantity,
+					ProductId: v.ProductId,
+				},
+			}
+		}
+		// - checkout
+		err = h.sv.Checkout(&i, s)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			case errors.Is(err, internal.ErrRepositoryCustomerNotFound):
+				response.Error(w, http.StatusConflict, "customer not found")
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				response.Error(w, http.StatusConflict, "product not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error saving checkout")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		iv := InvoiceCheckoutJSON{
+			InvoiceJSON: InvoiceJSON{
+				Id:         i.Id,
+				Datetime:   i.Datetime,
+				Total:      i.Total,
+				CustomerId: i.CustomerId,
+			},
+			Sales: make([]SaleJSON, len(s)),
+		}
+		for ix, v := range s {
+			iv.Sales[ix] = SaleJSON{
+				Id:        v.Id,
+				Quantity:  v.Quantity,
+				ProductId: v.ProductId,
+				InvoiceId: v.InvoiceId,
+			}
+		}
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "invoice checked out",
+			"data":    iv,
+		})
+	}
+}
+
+// RequestBodyInvoiceUpdate is a struct that represents the request body for replacing an invoice,
+// its total is computed from its sales
+type RequestBodyInvoiceUpdate struct {
+	Datetime   string `json:"datetime"`
+	CustomerId int    `json:"customer_id"`
+}
+
+// Update replaces an invoice
+func (h *InvoicesDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodyInvoiceUpdate
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error parsing request body")
+			return
+		}
+
+		// process
+		// - deserialize
+		i := internal.Invoice{
+			Id: id,
+			InvoiceAttributes: internal.InvoiceAttributes{
+				Datetime:   reqBody.Datetime,
+				CustomerId: reqBody.CustomerId,
+			},
+		}
+		// - update
+		err = h.sv.Update(&i)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryInvoiceNotFound):
+				response.Error(w, http.StatusNotFound, "invoice not found")
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error updating invoice")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "invoice updated",
+			"data": InvoiceJSON{
+				Id:         i.Id,
+				Datetime:   i.Datetime,
+				Total:      i.Total,
+				CustomerId: i.CustomerId,
+			},
+		})
+	}
+}
+
+// RequestBodyInvoicePatch is a struct that represents the request body for patching an invoice,
+// its total is computed from its sales
+type RequestBodyInvoicePatch struct {
+	Datetime   *string `json:"datetime"`
+	CustomerId *int    `json:"customer_id"`
+}
+
+// Patch updates the given fields of an invoice
+func (h *InvoicesDefault) Patch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodyInvoicePatch
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error parsing request body")
+			return
+		}
+
+		// process
+		i, err := h.sv.Patch(id, internal.InvoicePatch{
+			Datetime:   reqBody.Datetime,
+			CustomerId: reqBody.CustomerId,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryInvoiceNotFound):
+				response.Error(w, http.StatusNotFound, "invoice not found")
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error updating invoice")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "invoice updated",
+			"data": InvoiceJSON{
+				Id:         i.Id,
+				Datetime:   i.Datetime,
+				Total:      i.Total,
+				CustomerId: i.CustomerId,
+			},
+		})
+	}
+}
+
+// Delete deletes an invoice along with its sales
+func (h *InvoicesDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		err = h.sv.Delete(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryInvoiceNotFound):
+				response.Error(w, http.StatusNotFound, "invoice not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error deleting invoice")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusNoContent, nil)
+	}
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"app/internal"
 
 	"github.com/bootcamp-go/web/request"
 	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
 )
 
 // NewProductsDefault returns a new ProductsDefault
@@ -27,9 +30,13 @@ type ProductJSON struct {
 	Price       float64 `json:"price"`
 }
 
+// BestSellingJSON is a struct that represents a best-selling product in JSON format
 type BestSellingJSON struct {
-	Description string `json:"description"`
-	Total       int    `json:"total"`
+	Id          int     `json:"id"`
+	Description string  `json:"description"`
+	Units       int     `json:"units"`
+	Revenue     float64 `json:"revenue"`
+	Share       float64 `json:"share"`
 }
 
 // GetAll returns all products
@@ -62,20 +69,88 @@ func (h *ProductsDefault) GetAll() http.HandlerFunc {
 	}
 }
 
+// GetById returns a product by its id
+func (h *ProductsDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		p, err := h.sv.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				response.Error(w, http.StatusNotFound, "product not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error getting product")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "product found",
+			"data": ProductJSON{
+				Id:          p.Id,
+				Description: p.Description,
+				Price:       p.Price,
+			},
+		})
+	}
+}
+
 // GetBestSelling returns the best-selling products
 func (h *ProductsDefault) GetBestSelling() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		bestSellingProducts, err := h.sv.GetBestSelling()
+		// request
+		// - query parameter: limit (default 5)
+		var f internal.ProductBestSellingFilter
+		var err error
+		f.Limit, err = queryLimit(r, 5)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// - query parameter: order_by (quantity or revenue, default quantity)
+		switch v := internal.ProductBestSellingOrder(r.URL.Query().Get("order_by")); v {
+		case "":
+			f.OrderBy = internal.ProductBestSellingOrderQuantity
+		case internal.ProductBestSellingOrderQuantity, internal.ProductBestSellingOrderRevenue:
+			f.OrderBy = v
+		default:
+			response.Error(w, http.StatusBadRequest, "invalid order_by parameter, expected quantity or revenue")
+			return
+		}
+		// - query parameters: from, to
+		f.From, f.To, err = queryDateRange(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// process
+		bestSellingProducts, err := h.sv.GetBestSelling(f)
 		if err != nil {
 			response.Error(w, http.StatusInternalServerError, "error getting best-selling products")
 			return
 		}
 
+		// response
+		// - serialize
 		pJSON := make([]BestSellingJSON, len(bestSellingProducts))
 		for ix, v := range bestSellingProducts {
 			pJSON[ix] = BestSellingJSON{
+				Id:          v.Id,
 				Description: v.Description,
-				Total:       v.Total,
+				Units:       v.Units,
+				Revenue:     v.Revenue,
+				Share:       v.Share,
 			}
 		}
 
@@ -115,7 +190,12 @@ func (h *ProductsDefault) Create() http.HandlerFunc {
 		// - save
 		err = h.sv.Save(&p)
 		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "error creating product")
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error creating product")
+			}
 			return
 		}
 
@@ -132,3 +212,139 @@ func (h *ProductsDefault) Create() http.HandlerFunc {
 		})
 	}
 }
+
+// Update replaces a product
+func (h *ProductsDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodyProduct
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error parsing request body")
+			return
+		}
+
+		// process
+		// - deserialize
+		p := internal.Product{
+			Id: id,
+			ProductAttributes: internal.ProductAttributes{
+				Description: reqBody.Description,
+				Price:       reqBody.Price,
+			},
+		}
+		// - update
+		err = h.sv.Update(&p)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				response.Error(w, http.StatusNotFound, "product not found")
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error updating product")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "product updated",
+			"data": ProductJSON{
+				Id:          p.Id,
+				Description: p.Description,
+				Price:       p.Price,
+			},
+		})
+	}
+}
+
+// RequestBodyProductPatch is a struct that represents the request body for patching a product
+type RequestBodyProductPatch struct {
+	Description *string  `json:"description"`
+	Price       *float64 `json:"price"`
+}
+
+// Patch updates the given fields of a product
+func (h *ProductsDefault) Patch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodyProductPatch
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error parsing request body")
+			return
+		}
+
+		// process
+		p, err := h.sv.Patch(id, internal.ProductPatch{
+			Description: reqBody.Description,
+			Price:       reqBody.Price,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				response.Error(w, http.StatusNotFound, "product not found")
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error updating product")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "product updated",
+			"data": ProductJSON{
+				Id:          p.Id,
+				Description: p.Description,
+				Price:       p.Price,
+			},
+		})
+	}
+}
+
+// Delete deletes a product along with its sales
+func (h *ProductsDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		err = h.sv.Delete(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				response.Error(w, http.StatusNotFound, "product not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error deleting product")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusNoContent, nil)
+	}
+}
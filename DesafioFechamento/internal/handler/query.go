@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrQueryFromInvalid is returned when the query parameter from is invalid.
+	ErrQueryFromInvalid = errors.New("invalid from parameter, expected 2006-01-02 or 2006-01-02 15:04:05")
+	// ErrQueryToInvalid is returned when the query parameter to is invalid.
+	ErrQueryToInvalid = errors.New("invalid to parameter, expected 2006-01-02 or 2006-01-02 15:04:05")
+	// ErrQueryRangeInvalid is returned when from is after to.
+	ErrQueryRangeInvalid = errors.New("invalid range, from is after to")
+	// ErrQueryLimitInvalid is returned when the query parameter limit is invalid.
+	ErrQueryLimitInvalid = errors.New("invalid limit parameter, expected a positive integer")
+)
+
+// queryDateRange parses the query parameters from and to.
+// A date without time in to covers the whole day. Missing parameters are returned as zero.
+func queryDateRange(r *http.Request) (from, to time.Time, err error) {
+	if v := r.URL.Query().Get("from"); v != "" {
+		from, _, err = parseDate(v)
+		if err != nil {
+			err = ErrQueryFromInvalid
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		var dateOnly bool
+		to, dateOnly, err = parseDate(v)
+		if err != nil {
+			err = ErrQueryToInvalid
+			return
+		}
+		if dateOnly {
+			to = to.Add(24*time.Hour - time.Second)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		err = ErrQueryRangeInvalid
+		return
+	}
+	return
+}
+
+// parseDate parses a date formatted as time.DateOnly or time.DateTime.
+func parseDate(v string) (t time.Time, dateOnly bool, err error) {
+	t, err = time.Parse(time.DateOnly, v)
+	if err == nil {
+		dateOnly = true
+		return
+	}
+	t, err = time.Parse(time.DateTime, v)
+	return
+}
+
+// queryLimit parses the query parameter limit, returning def when it is missing.
+func queryLimit(r *http.Request, def int) (limit int, err error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		limit = def
+		return
+	}
+	limit, err = strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		err = ErrQueryLimitInvalid
+		return
+	}
+	return
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"app/internal"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// NewReportsDefault returns a new ReportsDefault
+func NewReportsDefault(sv internal.ServiceReport) *ReportsDefault {
+	return &ReportsDefault{sv: sv}
+}
+
+// ReportsDefault is a struct that returns the report handlers
+type ReportsDefault struct {
+	// sv is the report's service
+	sv internal.ServiceReport
+}
+
+// SalesReportBucketJSON is a struct that represents a bucket of the sales report in JSON format
+type SalesReportBucketJSON struct {
+	Period      string  `json:"period"`
+	ProductId   *int    `json:"product_id,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Condition   *int    `json:"condition,omitempty"`
+	Revenue     float64 `json:"revenue"`
+	Units       int     `json:"units"`
+	Invoices    int     `json:"invoices"`
+}
+
+// GetSales returns the revenue, units and invoices bucketed by period
+func (h *ReportsDefault) GetSales() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: interval (day, week or month, default month)
+		f := internal.SalesReportFilter{
+			Interval: internal.SalesReportIntervalMonth,
+		}
+		switch v := internal.SalesReportInterval(r.URL.Query().Get("interval")); v {
+		case "":
+		case internal.SalesReportIntervalDay, internal.SalesReportIntervalWeek, internal.SalesReportIntervalMonth:
+			f.Interval = v
+		default:
+			response.Error(w, http.StatusBadRequest, "invalid interval parameter, expected day, week or month")
+			return
+		}
+		// - query parameter: group_by (product or condition)
+		switch v := internal.SalesReportGroup(r.URL.Query().Get("group_by")); v {
+		case internal.SalesReportGroupNone, internal.SalesReportGroupProduct, internal.SalesReportGroupCondition:
+			f.GroupBy = v
+		default:
+			response.Error(w, http.StatusBadRequest, "invalid group_by parameter, expected product or condition")
+			return
+		}
+		// - query parameters: from, to
+		var err error
+		f.From, f.To, err = queryDateRange(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// process
+		b, err := h.sv.GetSales(f)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryReportInvalid):
+				response.Error(w, http.StatusBadRequest, "invalid report parameters")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error getting sales report")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		bJSON := make([]SalesReportBucketJSON, len(b))
+		for ix, v := range b {
+			bJSON[ix] = SalesReportBucketJSON{
+				Period:      v.Period,
+				ProductId:   v.ProductId,
+				Description: v.Description,
+				Condition:   v.Condition,
+				Revenue:     v.Revenue,
+				Units:       v.Units,
+				Invoices:    v.Invoices,
+			}
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "sales report found",
+			"data":    bJSON,
+		})
+	}
+}
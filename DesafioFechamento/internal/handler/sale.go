@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"app/internal"
 
 	"github.com/bootcamp-go/web/request"
 	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
 )
 
 // NewSalesDefault returns a new SalesDefault
@@ -59,6 +62,43 @@ func (h *SalesDefault) GetAll() http.HandlerFunc {
 	}
 }
 
+// GetById returns a sale by its id
+func (h *SalesDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		s, err := h.sv.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositorySaleNotFound):
+				response.Error(w, http.StatusNotFound, "sale not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error getting sale")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "sale found",
+			"data": SaleJSON{
+				Id:        s.Id,
+				Quantity:  s.Quantity,
+				ProductId: s.ProductId,
+				InvoiceId: s.InvoiceId,
+			},
+		})
+	}
+}
+
 // RequestBodySale is a struct that represents the request body for a sale
 type RequestBodySale struct {
 	Quantity int `json:"quantity"`
@@ -89,7 +129,12 @@ func (h *SalesDefault) Create() http.HandlerFunc {
 		// - save
 		err = h.sv.Save(&s)
 		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "error saving sale")
+			switch {
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error saving sale")
+			}
 			return
 		}
 
@@ -106,4 +151,145 @@ func (h *SalesDefault) Create() http.HandlerFunc {
 			"data":    sa,
 		})
 	}
-}
\ No newline at end of file
+}
+
+// Update replaces a sale
+func (h *SalesDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodySale
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error parsing request body")
+			return
+		}
+
+		// process
+		// - deserialize
+		s := internal.Sale{
+			Id: id,
+			SaleAttributes: internal.SaleAttributes{
+				Quantity:  reqBody.Quantity,
+				ProductId: reqBody.ProductId,
+				InvoiceId: reqBody.InvoiceId,
+			},
+		}
+		// - update
+		err = h.sv.Update(&s)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositorySaleNotFound):
+				response.Error(w, http.StatusNotFound, "sale not found")
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error updating sale")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "sale updated",
+			"data": SaleJSON{
+				Id:        s.Id,
+				Quantity:  s.Quantity,
+				ProductId: s.ProductId,
+				InvoiceId: s.InvoiceId,
+			},
+		})
+	}
+}
+
+// RequestBodySalePatch is a struct that represents the request body for patching a sale
+type RequestBodySalePatch struct {
+	Quantity  *int `json:"quantity"`
+	ProductId *int `json:"product_id"`
+	InvoiceId *int `json:"invoice_id"`
+}
+
+// Patch updates the given fields of a sale
+func (h *SalesDefault) Patch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var reqBody RequestBodySalePatch
+		err = request.JSON(r, &reqBody)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "error parsing request body")
+			return
+		}
+
+		// process
+		s, err := h.sv.Patch(id, internal.SalePatch{
+			Quantity:  reqBody.Quantity,
+			ProductId: reqBody.ProductId,
+			InvoiceId: reqBody.InvoiceId,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositorySaleNotFound):
+				response.Error(w, http.StatusNotFound, "sale not found")
+			case errors.Is(err, internal.ErrServiceInvalidField), errors.Is(err, internal.ErrServiceReferenceNotFound):
+				responseFieldsError(w, err)
+			default:
+				response.Error(w, http.StatusInternalServerError, "error updating sale")
+			}
+			return
+		}
+
+		// response
+		// - serialize
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "sale updated",
+			"data": SaleJSON{
+				Id:        s.Id,
+				Quantity:  s.Quantity,
+				ProductId: s.ProductId,
+				InvoiceId: s.InvoiceId,
+			},
+		})
+	}
+}
+
+// Delete deletes a sale
+func (h *SalesDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		err = h.sv.Delete(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositorySaleNotFound):
+				response.Error(w, http.StatusNotFound, "sale not found")
+			default:
+				response.Error(w, http.StatusInternalServerError, "error deleting sale")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusNoContent, nil)
+	}
+}
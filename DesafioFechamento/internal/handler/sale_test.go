@@ -0,0 +1,58 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"app/internal"
+	"app/internal/handler"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serviceSaleStub is a ServiceSale failing every save with err.
+type serviceSaleStub struct {
+	internal.ServiceSale
+	err error
+}
+
+func (s *serviceSaleStub) Save(sl *internal.Sale) error {
+	return s.err
+}
+
+func TestSalesDefault_Create(t *testing.T) {
+	t.Run("error - invalid field is 422", func(t *testing.T) {
+		hd := handler.NewSalesDefault(&serviceSaleStub{err: &internal.FieldsError{
+			Err:    internal.ErrServiceInvalidField,
+			Fields: []internal.FieldError{{Field: "quantity", Message: "must be greater than 0"}},
+		}})
+		req := httptest.NewRequest(http.MethodPost, "/sales", strings.NewReader(`{"quantity":0,"product_id":1,"invoice_id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		hd.Create()(res, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.JSONEq(t, `{"status":"Unprocessable Entity","message":"invalid fields","errors":[{"field":"quantity","error":"must be greater than 0"}]}`, res.Body.String())
+	})
+
+	t.Run("error - reference not found is 409", func(t *testing.T) {
+		hd := handler.NewSalesDefault(&serviceSaleStub{err: &internal.FieldsError{
+			Err: internal.ErrServiceReferenceNotFound,
+			Fields: []internal.FieldError{
+				{Field: "product_id", Message: "product not found"},
+				{Field: "invoice_id", Message: "invoice not found"},
+			},
+		}})
+		req := httptest.NewRequest(http.MethodPost, "/sales", strings.NewReader(`{"quantity":1,"product_id":2,"invoice_id":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		hd.Create()(res, req)
+
+		require.Equal(t, http.StatusConflict, res.Code)
+		require.JSONEq(t, `{"status":"Conflict","message":"referenced entities not found","errors":[{"field":"product_id","error":"product not found"},{"field":"invoice_id","error":"invoice not found"}]}`, res.Body.String())
+	})
+}
@@ -16,4 +16,26 @@ type Invoice struct {
 	Id int
 	// InvoiceAttributes is the attributes of the invoice.
 	InvoiceAttributes
-}
\ No newline at end of file
+}
+
+// InvoicePatch is the struct that represents the fields of an invoice to be patched.
+// A nil field is left untouched. The total is computed from the sales, so it is not patched.
+type InvoicePatch struct {
+	// Datetime is the datetime of the invoice.
+	Datetime *string
+	// CustomerId is the customer id of the invoice.
+	CustomerId *int
+}
+
+// InvoiceDiscrepancy is the struct that represents the difference between the stored total of an invoice
+// and the total computed from its sales.
+type InvoiceDiscrepancy struct {
+	// InvoiceId is the id of the invoice.
+	InvoiceId int
+	// StoredTotal is the total saved in the invoice.
+	StoredTotal float64
+	// ComputedTotal is the total computed from the sales of the invoice.
+	ComputedTotal float64
+	// Difference is the computed total minus the stored total.
+	Difference float64
+}
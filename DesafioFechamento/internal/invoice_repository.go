@@ -1,9 +1,31 @@
 package internal
 
+import "errors"
+
+var (
+	// ErrRepositoryInvoiceNotFound is returned when an invoice is not found.
+	ErrRepositoryInvoiceNotFound = errors.New("repository: invoice not found")
+)
+
 // RepositoryInvoice is the interface that wraps the basic methods that an invoice repository should implement.
 type RepositoryInvoice interface {
 	// FindAll returns all invoices
 	FindAll() (i []Invoice, err error)
+	// FindById returns an invoice by its id
+	FindById(id int) (i Invoice, err error)
+	// GetDiscrepancies returns the stored and computed totals of all invoices
+	GetDiscrepancies() (d []InvoiceDiscrepancy, err error)
+	// GetDiscrepancyById returns the stored and computed totals of an invoice
+	GetDiscrepancyById(id int) (d InvoiceDiscrepancy, err error)
 	// Save saves an invoice
 	Save(i *Invoice) (err error)
-}
\ No newline at end of file
+	// Update updates an invoice, setting its total to the total of its sales
+	Update(i *Invoice) (err error)
+	// Delete deletes an invoice along with its sales
+	Delete(id int) (err error)
+	// Checkout saves an invoice together with its sales in a single transaction,
+	// computing the invoice total from the products prices
+	Checkout(i *Invoice, s []Sale) (err error)
+	// UpdateTotals sets the total of each invoice to the total of its sales in a single transaction
+	UpdateTotals(d []InvoiceDiscrepancy) (err error)
+}
@@ -4,6 +4,21 @@ package internal
 type ServiceInvoice interface {
 	// FindAll returns all invoices
 	FindAll() (i []Invoice, err error)
+	// FindById returns an invoice by its id
+	FindById(id int) (i Invoice, err error)
+	// Reconcile returns the invoices whose stored total drifts from their sales,
+	// writing the computed totals back when apply is true
+	Reconcile(apply bool) (d []InvoiceDiscrepancy, err error)
+	// GetDiscrepancy returns the stored and computed totals of an invoice
+	GetDiscrepancy(id int) (d InvoiceDiscrepancy, err error)
 	// Save saves an invoice
 	Save(i *Invoice) (err error)
-}
\ No newline at end of file
+	// Checkout saves an invoice and its sales atomically
+	Checkout(i *Invoice, s []Sale) (err error)
+	// Update updates an invoice
+	Update(i *Invoice) (err error)
+	// Patch updates the given fields of an invoice
+	Patch(id int, p InvoicePatch) (i Invoice, err error)
+	// Delete deletes an invoice
+	Delete(id int) (err error)
+}
@@ -4,4 +4,6 @@ package internal
 type StorageInvoice interface {
 	// FindAll returns all invoices.
 	FindAll() (i []Invoice, err error)
+	// SaveAll replaces the stored invoices with i.
+	SaveAll(i []Invoice) (err error)
 }
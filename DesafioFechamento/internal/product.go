@@ -1,5 +1,7 @@
 package internal
 
+import "time"
+
 // ProductAttributes is the struct that represents the attributes of a product.
 type ProductAttributes struct {
 	// Description is the description of the product.
@@ -16,7 +18,47 @@ type Product struct {
 	ProductAttributes
 }
 
+// ProductPatch is the struct that represents the fields of a product to be patched.
+// A nil field is left untouched.
+type ProductPatch struct {
+	// Description is the description of the product.
+	Description *string
+	// Price is the price of the product.
+	Price *float64
+}
+
+// ProductBestSellingOrder is the ranking criteria of the best-selling products.
+type ProductBestSellingOrder string
+
+const (
+	// ProductBestSellingOrderQuantity ranks the products by units sold.
+	ProductBestSellingOrderQuantity ProductBestSellingOrder = "quantity"
+	// ProductBestSellingOrderRevenue ranks the products by revenue.
+	ProductBestSellingOrderRevenue ProductBestSellingOrder = "revenue"
+)
+
+// ProductBestSellingFilter is the struct that represents the parameters of the best-selling products report.
+type ProductBestSellingFilter struct {
+	// Limit is the maximum number of products. Zero means no limit.
+	Limit int
+	// OrderBy is the ranking criteria.
+	OrderBy ProductBestSellingOrder
+	// From is the lower bound of the invoices datetime, inclusive. Zero means no lower bound.
+	From time.Time
+	// To is the upper bound of the invoices datetime, inclusive. Zero means no upper bound.
+	To time.Time
+}
+
+// ProductBestSelling is the struct that represents the sales of a product.
 type ProductBestSelling struct {
+	// Id is the unique identifier of the product.
+	Id int
+	// Description is the description of the product.
 	Description string
-	Total       int
+	// Units is the quantity of the product sold.
+	Units int
+	// Revenue is the sum of quantity * price of the sales of the product.
+	Revenue float64
+	// Share is the percentage of the total revenue of the period that comes from the product.
+	Share float64
 }
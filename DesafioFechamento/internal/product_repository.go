@@ -1,11 +1,28 @@
 package internal
 
+import "errors"
+
+var (
+	// ErrRepositoryProductNotFound is returned when a product is not found.
+	ErrRepositoryProductNotFound = errors.New("repository: product not found")
+	// ErrRepositoryProductOrderInvalid is returned when the ranking criteria is not supported.
+	ErrRepositoryProductOrderInvalid = errors.New("repository: invalid product order")
+)
+
 // RepositoryProduct is the interface that wraps the basic methods that a product repository must have.
 type RepositoryProduct interface {
 	// FindAll returns all products saved in the database.
 	FindAll() (p []Product, err error)
+	// FindById returns a product by its id.
+	FindById(id int) (p Product, err error)
 
-	GetBestSelling() (p []ProductBestSelling, err error)
+	// GetBestSelling returns the best-selling products, filtered and ranked by f.
+	GetBestSelling(f ProductBestSellingFilter) (p []ProductBestSelling, err error)
 	// Save saves a product into the database.
 	Save(p *Product) (err error)
+	// Update updates a product in the database, updating the total of the invoices that contain it.
+	Update(p *Product) (err error)
+	// Delete deletes a product from the database, along with its sales,
+	// updating the total of the invoices that contained it.
+	Delete(id int) (err error)
 }
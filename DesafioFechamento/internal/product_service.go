@@ -4,8 +4,17 @@ package internal
 type ServiceProduct interface {
 	// FindAll returns all products.
 	FindAll() (p []Product, err error)
+	// FindById returns a product by its id.
+	FindById(id int) (p Product, err error)
 
-	GetBestSelling() (p []ProductBestSelling, err error)
+	// GetBestSelling returns the best-selling products.
+	GetBestSelling(f ProductBestSellingFilter) (p []ProductBestSelling, err error)
 	// Save saves a product.
 	Save(p *Product) (err error)
+	// Update updates a product.
+	Update(p *Product) (err error)
+	// Patch updates the given fields of a product.
+	Patch(id int, pp ProductPatch) (p Product, err error)
+	// Delete deletes a product.
+	Delete(id int) (err error)
 }
@@ -4,4 +4,6 @@ package internal
 type StorageProduct interface {
 	// FindAll returns all products.
 	FindAll() (p []Product, err error)
+	// SaveAll replaces the stored products with p.
+	SaveAll(p []Product) (err error)
 }
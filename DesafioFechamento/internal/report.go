@@ -0,0 +1,57 @@
+package internal
+
+import "time"
+
+// SalesReportInterval is the size of the buckets of the sales report.
+type SalesReportInterval string
+
+const (
+	// SalesReportIntervalDay buckets the sales by day.
+	SalesReportIntervalDay SalesReportInterval = "day"
+	// SalesReportIntervalWeek buckets the sales by week, starting on monday.
+	SalesReportIntervalWeek SalesReportInterval = "week"
+	// SalesReportIntervalMonth buckets the sales by month.
+	SalesReportIntervalMonth SalesReportInterval = "month"
+)
+
+// SalesReportGroup is the dimension the buckets of the sales report are split by.
+type SalesReportGroup string
+
+const (
+	// SalesReportGroupNone does not split the buckets.
+	SalesReportGroupNone SalesReportGroup = ""
+	// SalesReportGroupProduct splits the buckets by product.
+	SalesReportGroupProduct SalesReportGroup = "product"
+	// SalesReportGroupCondition splits the buckets by condition of the customer.
+	SalesReportGroupCondition SalesReportGroup = "condition"
+)
+
+// SalesReportFilter is the struct that represents the parameters of the sales report.
+type SalesReportFilter struct {
+	// Interval is the size of the buckets.
+	Interval SalesReportInterval
+	// GroupBy is the dimension the buckets are split by.
+	GroupBy SalesReportGroup
+	// From is the lower bound of the invoices datetime, inclusive. Zero means no lower bound.
+	From time.Time
+	// To is the upper bound of the invoices datetime, inclusive. Zero means no upper bound.
+	To time.Time
+}
+
+// SalesReportBucket is the struct that represents the sales of a period.
+type SalesReportBucket struct {
+	// Period is the first day of the bucket, formatted as 2006-01-02.
+	Period string
+	// ProductId is the id of the product, when grouped by product.
+	ProductId *int
+	// Description is the description of the product, when grouped by product.
+	Description string
+	// Condition is the condition of the customers, when grouped by condition.
+	Condition *int
+	// Revenue is the sum of quantity * price of the sales.
+	Revenue float64
+	// Units is the sum of the quantities of the sales.
+	Units int
+	// Invoices is the number of distinct invoices.
+	Invoices int
+}
@@ -0,0 +1,14 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrRepositoryReportInvalid is returned when the parameters of a report are not supported.
+	ErrRepositoryReportInvalid = errors.New("repository: invalid report parameters")
+)
+
+// RepositoryReport is the interface that wraps the basic methods that a report repository should implement.
+type RepositoryReport interface {
+	// GetSales returns the sales bucketed by period, filtered by f.
+	GetSales(f SalesReportFilter) (b []SalesReportBucket, err error)
+}
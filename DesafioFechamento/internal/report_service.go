@@ -0,0 +1,7 @@
+package internal
+
+// ServiceReport is the interface that wraps the basic methods that a report service should implement.
+type ServiceReport interface {
+	// GetSales returns the sales bucketed by period.
+	GetSales(f SalesReportFilter) (b []SalesReportBucket, err error)
+}
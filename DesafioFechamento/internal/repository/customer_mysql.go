@@ -2,40 +2,14 @@ package repository
 
 import (
 	"database/sql"
-	"log"
+	"errors"
+	"strings"
 
 	"app/internal"
 )
 
-func NewCustomersMySQL(db *sql.DB, storage internal.StorageCustomer) *CustomersMySQL {
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM customers").Scan(&count)
-	if err != nil && storage != nil {
-		log.Printf("Error checking customers table: %v", err)
-		return &CustomersMySQL{db}
-	}
-
-	if count > 0 {
-		return &CustomersMySQL{db}
-	}
-
-	if storage != nil {
-		customers, err := storage.FindAll()
-		if err == nil {
-			for _, cust := range customers {
-				_, err := db.Exec(
-					"INSERT INTO customers (`first_name`, `last_name`, `condition`) VALUES (?, ?, ?)",
-					cust.FirstName, cust.LastName, cust.Condition,
-				)
-				if err != nil {
-					log.Printf("Error inserting customer %v: %v", cust, err)
-				}
-			}
-		} else {
-			log.Printf("Error fetching customers from storage: %v", err)
-		}
-	}
-
+// NewCustomersMySQL creates new mysql repository for customer entity.
+func NewCustomersMySQL(db *sql.DB) *CustomersMySQL {
 	return &CustomersMySQL{db}
 }
 
@@ -47,8 +21,13 @@ type CustomersMySQL struct {
 
 // FindAll returns all customers from the database.
 func (r *CustomersMySQL) FindAll() (c []internal.Customer, err error) {
+	return findAllCustomers(r.db)
+}
+
+// findAllCustomers returns all customers read by q, the database or a transaction.
+func findAllCustomers(q queryer) (c []internal.Customer, err error) {
 	// execute the query
-	rows, err := r.db.Query("SELECT `id`, `first_name`, `last_name`, `condition` FROM customers")
+	rows, err := q.Query("SELECT `id`, `first_name`, `last_name`, `condition` FROM customers")
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +52,25 @@ func (r *CustomersMySQL) FindAll() (c []internal.Customer, err error) {
 	return
 }
 
-func (r *CustomersMySQL) GetTotalValues() (totalValues []internal.CustomerTotalValue, err error) {
-	rows, err := r.db.Query(`
+// FindById returns a customer by its id from the database.
+func (r *CustomersMySQL) FindById(id int) (c internal.Customer, err error) {
+	// execute the query
+	row := r.db.QueryRow("SELECT `id`, `first_name`, `last_name`, `condition` FROM customers WHERE `id` = ?", id)
+	err = row.Scan(&c.Id, &c.FirstName, &c.LastName, &c.Condition)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryCustomerNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetTotalValues returns the total spent by condition of the customers from the database.
+func (r *CustomersMySQL) GetTotalValues(f internal.CustomerReportFilter) (totalValues []internal.CustomerTotalValue, err error) {
+	where, args := customerReportWhere(f)
+	query := `
 		SELECT 
 			c.condition, 
 			ROUND(SUM(s.quantity * p.price), 2) AS total_value
@@ -86,9 +82,14 @@ func (r *CustomersMySQL) GetTotalValues() (totalValues []internal.CustomerTotalV
 			sales s ON i.id = s.invoice_id
 		JOIN 
 			products p ON s.product_id = p.id
+		` + where + `
 		GROUP BY 
-			c.condition;
-	`)
+			c.condition`
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +112,10 @@ func (r *CustomersMySQL) GetTotalValues() (totalValues []internal.CustomerTotalV
 	return
 }
 
-func (r *CustomersMySQL) GetSpentMoreMoney() (spentMoreMoney []internal.CustomerSpentMoreMoney, err error) {
-	rows, err := r.db.Query(`
+// GetSpentMoreMoney returns the customers that spent more money from the database.
+func (r *CustomersMySQL) GetSpentMoreMoney(f internal.CustomerReportFilter) (spentMoreMoney []internal.CustomerSpentMoreMoney, err error) {
+	where, args := customerReportWhere(f)
+	query := `
 		SELECT
 		    c.first_name,
 		    c.last_name,
@@ -125,14 +128,16 @@ func (r *CustomersMySQL) GetSpentMoreMoney() (spentMoreMoney []internal.Customer
 		    sales s ON i.id = s.invoice_id
 		JOIN
 		    products p ON s.product_id = p.id
-		WHERE
-    		c.condition = 1 
+		` + where + `
 		GROUP BY
 		    c.first_name, c.last_name
 		ORDER BY
-		    total_spent DESC
-		LIMIT 5;
-	`)
+		    total_spent DESC`
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +160,94 @@ func (r *CustomersMySQL) GetSpentMoreMoney() (spentMoreMoney []internal.Customer
 	return spentMoreMoney, nil
 }
 
+// GetPurchases returns the invoices of a customer with their sales from the database, oldest first.
+func (r *CustomersMySQL) GetPurchases(id int) (p []internal.CustomerPurchase, err error) {
+	// execute the query
+	rows, err := r.db.Query(`
+		SELECT
+			i.id,
+			i.datetime,
+			COALESCE(i.total, 0),
+			s.id,
+			s.quantity,
+			p.id,
+			p.description,
+			p.price,
+			ROUND(s.quantity * p.price, 2) AS line_total
+		FROM
+			invoices i
+		LEFT JOIN
+			sales s ON i.id = s.invoice_id
+		LEFT JOIN
+			products p ON s.product_id = p.id
+		WHERE
+			i.customer_id = ?
+		ORDER BY
+			i.datetime, i.id, s.id;
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// iterate over the rows, one per sale
+	for rows.Next() {
+		var iv internal.Invoice
+		var saleId, quantity, productId sql.NullInt64
+		var description sql.NullString
+		var price, total sql.NullFloat64
+		err := rows.Scan(&iv.Id, &iv.Datetime, &iv.Total, &saleId, &quantity, &productId, &description, &price, &total)
+		if err != nil {
+			return nil, err
+		}
+		iv.CustomerId = id
+
+		// start a new purchase when the invoice changes
+		if len(p) == 0 || p[len(p)-1].Id != iv.Id {
+			p = append(p, internal.CustomerPurchase{Invoice: iv})
+		}
+		// invoices without sales have no lines
+		if !saleId.Valid {
+			continue
+		}
+		p[len(p)-1].Lines = append(p[len(p)-1].Lines, internal.CustomerPurchaseLine{
+			SaleId:      int(saleId.Int64),
+			ProductId:   int(productId.Int64),
+			Description: description.String,
+			UnitPrice:   price.Float64,
+			Quantity:    int(quantity.Int64),
+			Total:       total.Float64,
+		})
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// customerReportWhere returns the WHERE clause and its arguments for the filter of the customer reports.
+func customerReportWhere(f internal.CustomerReportFilter) (where string, args []any) {
+	var conditions []string
+	if !f.From.IsZero() {
+		conditions = append(conditions, "i.datetime >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "i.datetime <= ?")
+		args = append(args, f.To)
+	}
+	if f.Condition != nil {
+		conditions = append(conditions, "c.condition = ?")
+		args = append(args, *f.Condition)
+	}
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	return
+}
+
 // Save saves the customer into the database.
 func (r *CustomersMySQL) Save(c *internal.Customer) (err error) {
 	// execute the query
@@ -177,3 +270,35 @@ func (r *CustomersMySQL) Save(c *internal.Customer) (err error) {
 
 	return
 }
+
+// Update updates the customer in the database.
+func (r *CustomersMySQL) Update(c *internal.Customer) (err error) {
+	// execute the query
+	_, err = r.db.Exec(
+		"UPDATE customers SET `first_name` = ?, `last_name` = ?, `condition` = ? WHERE `id` = ?",
+		(*c).FirstName, (*c).LastName, (*c).Condition, (*c).Id,
+	)
+	return
+}
+
+// Delete deletes the customer from the database.
+// Its invoices and their sales are removed by the ON DELETE CASCADE foreign keys.
+func (r *CustomersMySQL) Delete(id int) (err error) {
+	// execute the query
+	res, err := r.db.Exec("DELETE FROM customers WHERE `id` = ?", id)
+	if err != nil {
+		return
+	}
+
+	// check the customer existed
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rows == 0 {
+		err = internal.ErrRepositoryCustomerNotFound
+		return
+	}
+
+	return
+}
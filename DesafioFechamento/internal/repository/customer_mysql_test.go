@@ -1,12 +1,15 @@
 package repository_test
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"app/internal"
 	"app/internal/repository"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCustomersMySQL_Save(t *testing.T) {
@@ -16,7 +19,7 @@ func TestCustomersMySQL_Save(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.NewCustomersMySQL(db, nil)
+	repo := repository.NewCustomersMySQL(db)
 	mock.ExpectExec("INSERT INTO customers").
 		WithArgs("John", "Doe", 1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
@@ -45,7 +48,7 @@ func TestCustomersMySQL_GetTotalValues(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.NewCustomersMySQL(db, nil)
+	repo := repository.NewCustomersMySQL(db)
 
 	rows := sqlmock.NewRows([]string{"condition", "total_value"}).
 		AddRow(1, 100.00).
@@ -54,7 +57,7 @@ func TestCustomersMySQL_GetTotalValues(t *testing.T) {
 	mock.ExpectQuery(`(?i)SELECT c.condition,\s*ROUND\(SUM\(s.quantity \* p.price\), 2\)`).
 		WillReturnRows(rows)
 
-	totalValues, err := repo.GetTotalValues()
+	totalValues, err := repo.GetTotalValues(internal.CustomerReportFilter{})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -74,6 +77,38 @@ func TestCustomersMySQL_GetTotalValues(t *testing.T) {
 	}
 }
 
+func TestCustomersMySQL_GetTotalValues_Filtered(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCustomersMySQL(db)
+
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC)
+	condition := 0
+	rows := sqlmock.NewRows([]string{"condition", "total_value"}).
+		AddRow(0, 75.25)
+
+	mock.ExpectQuery(`(?i)WHERE i.datetime >= \? AND i.datetime <= \? AND c.condition = \? GROUP BY c.condition LIMIT \?`).
+		WithArgs(from, to, 0, 10).
+		WillReturnRows(rows)
+
+	totalValues, err := repo.GetTotalValues(internal.CustomerReportFilter{
+		From:      from,
+		To:        to,
+		Limit:     10,
+		Condition: &condition,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, totalValues, 1)
+	require.Equal(t, 75.25, totalValues[0].TotalValue)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCustomersMySQL_GetSpentMoreMoney(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -81,15 +116,17 @@ func TestCustomersMySQL_GetSpentMoreMoney(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := repository.NewCustomersMySQL(db, nil)
+	repo := repository.NewCustomersMySQL(db)
 
 	rows := sqlmock.NewRows([]string{"first_name", "last_name", "total_spent"}).
 		AddRow("John", "Doe", 200.00).
 		AddRow("Jane", "Doe", 150.00)
 	mock.ExpectQuery("SELECT c.first_name, c.last_name, ROUND").
+		WithArgs(1, 5).
 		WillReturnRows(rows)
 
-	spentMoreMoney, err := repo.GetSpentMoreMoney()
+	condition := 1
+	spentMoreMoney, err := repo.GetSpentMoreMoney(internal.CustomerReportFilter{Limit: 5, Condition: &condition})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -105,3 +142,96 @@ func TestCustomersMySQL_GetSpentMoreMoney(t *testing.T) {
 		t.Errorf("unfulfilled expectations: %s", err)
 	}
 }
+
+func TestCustomersMySQL_FindById(t *testing.T) {
+	t.Run("success - customer fetched", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewCustomersMySQL(db)
+
+		rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "condition"}).
+			AddRow(1, "John", "Doe", 1)
+		mock.ExpectQuery("SELECT `id`, `first_name`, `last_name`, `condition` FROM customers WHERE `id` = ?").
+			WithArgs(1).
+			WillReturnRows(rows)
+
+		customer, err := repo.FindById(1)
+
+		require.NoError(t, err)
+		require.Equal(t, "Doe", customer.LastName)
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewCustomersMySQL(db)
+
+		mock.ExpectQuery("SELECT `id`, `first_name`, `last_name`, `condition` FROM customers WHERE `id` = ?").
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err = repo.FindById(99)
+
+		require.ErrorIs(t, err, internal.ErrRepositoryCustomerNotFound)
+	})
+}
+
+func TestCustomersMySQL_Delete(t *testing.T) {
+	t.Run("error - customer not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewCustomersMySQL(db)
+
+		mock.ExpectExec("DELETE FROM customers").
+			WithArgs(99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = repo.Delete(99)
+
+		require.ErrorIs(t, err, internal.ErrRepositoryCustomerNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCustomersMySQL_GetPurchases(t *testing.T) {
+	t.Run("success - sales grouped by invoice", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewCustomersMySQL(db)
+
+		rows := sqlmock.NewRows([]string{"id", "datetime", "total", "sale_id", "quantity", "product_id", "description", "price", "line_total"}).
+			AddRow(1, "2022-01-01 10:00:00", 30.5, 1, 2, 10, "Apple", 10.0, 20.0).
+			AddRow(1, "2022-01-01 10:00:00", 30.5, 2, 1, 11, "Pear", 10.5, 10.5).
+			AddRow(2, "2022-02-01 10:00:00", 0.0, nil, nil, nil, nil, nil, nil)
+		mock.ExpectQuery("SELECT (.+) FROM invoices i LEFT JOIN sales s (.+) WHERE i.customer_id = ?").
+			WithArgs(1).
+			WillReturnRows(rows)
+
+		purchases, err := repo.GetPurchases(1)
+
+		require.NoError(t, err)
+		require.Len(t, purchases, 2)
+		require.Equal(t, 1, purchases[0].CustomerId)
+		require.Len(t, purchases[0].Lines, 2)
+		require.Equal(t, "Pear", purchases[0].Lines[1].Description)
+		require.Equal(t, 10.5, purchases[0].Lines[1].Total)
+		require.Empty(t, purchases[1].Lines)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"app/internal"
+)
+
+// queryer is the interface that wraps the Query method, implemented by the database and its transactions.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// NewExportMySQL creates new mysql repository for exporting the database.
+func NewExportMySQL(db *sql.DB) *ExportMySQL {
+	return &ExportMySQL{db}
+}
+
+// ExportMySQL is the MySQL repository implementation for exporting the database.
+type ExportMySQL struct {
+	// db is the database connection.
+	db *sql.DB
+}
+
+// Snapshot returns every customer, product, invoice and sale of the database.
+// The tables are read in a single read-only repeatable read transaction, so every read sees
+// the same consistent snapshot of the database and concurrent writes do not break the references.
+func (r *ExportMySQL) Snapshot() (d internal.SeedData, err error) {
+	// start the transaction
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	// read the tables
+	d.Customers, err = findAllCustomers(tx)
+	if err != nil {
+		return
+	}
+	d.Products, err = findAllProducts(tx)
+	if err != nil {
+		return
+	}
+	d.Invoices, err = findAllInvoices(tx)
+	if err != nil {
+		return
+	}
+	d.Sales, err = findAllSales(tx)
+	if err != nil {
+		return
+	}
+
+	// end the transaction
+	err = tx.Commit()
+	return
+}
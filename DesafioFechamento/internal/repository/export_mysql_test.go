@@ -0,0 +1,69 @@
+package repository_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"app/internal"
+	"app/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportMySQL_Snapshot(t *testing.T) {
+	t.Run("success - tables read in one transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewExportMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `first_name`, `last_name`, `condition` FROM customers")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "condition"}).AddRow(1, "John", "Doe", 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `description`, `price` FROM products")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description", "price"}).AddRow(7, "Apple", 1.5))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `datetime`, `total`, `customer_id` FROM invoices")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "datetime", "total", "customer_id"}).AddRow(3, "2022-01-01", 3.0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `quantity`, `product_id`, `invoice_id` FROM sales")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "product_id", "invoice_id"}).AddRow(4, 2, 7, 3))
+		mock.ExpectCommit()
+
+		d, err := repo.Snapshot()
+
+		require.NoError(t, err)
+		require.Equal(t, internal.SeedData{
+			Customers: []internal.Customer{{Id: 1, CustomerAttributes: internal.CustomerAttributes{FirstName: "John", LastName: "Doe", Condition: 1}}},
+			Products:  []internal.Product{{Id: 7, ProductAttributes: internal.ProductAttributes{Description: "Apple", Price: 1.5}}},
+			Invoices:  []internal.Invoice{{Id: 3, InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-01", Total: 3, CustomerId: 1}}},
+			Sales:     []internal.Sale{{Id: 4, SaleAttributes: internal.SaleAttributes{Quantity: 2, ProductId: 7, InvoiceId: 3}}},
+		}, d)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query fails and transaction is rolled back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewExportMySQL(db)
+
+		errQuery := errors.New("connection lost")
+		mock.ExpectBegin()
+		mock.ExpectQuery("FROM customers").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "condition"}))
+		mock.ExpectQuery("FROM products").WillReturnError(errQuery)
+		mock.ExpectRollback()
+
+		_, err = repo.Snapshot()
+
+		require.ErrorIs(t, err, errQuery)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
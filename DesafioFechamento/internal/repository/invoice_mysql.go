@@ -2,41 +2,14 @@ package repository
 
 import (
 	"database/sql"
-	"log"
+	"errors"
+	"math"
 
 	"app/internal"
 )
 
 // NewInvoicesMySQL creates new mysql repository for invoice entity.
-func NewInvoicesMySQL(db *sql.DB, storage internal.StorageInvoice) *InvoicesMySQL {
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM invoices").Scan(&count)
-	if err != nil && storage != nil {
-		log.Printf("Error checking invoices table: %v", err)
-		return &InvoicesMySQL{db}
-	}
-
-	if count > 0 {
-		return &InvoicesMySQL{db}
-	}
-
-	if storage != nil {
-		invoices, err := storage.FindAll()
-		if err == nil {
-			for _, invo := range invoices {
-				_, err := db.Exec(
-					"INSERT INTO invoices (`datetime`, `total`, `customer_id`) VALUES (?, ?, ?)",
-					invo.Datetime, invo.Total, invo.CustomerId,
-				)
-				if err != nil {
-					log.Printf("Error inserting invoice %v: %v", invo, err)
-				}
-			}
-		} else {
-			log.Printf("Error fetching invoices from storage: %v", err)
-		}
-	}
-
+func NewInvoicesMySQL(db *sql.DB) *InvoicesMySQL {
 	return &InvoicesMySQL{db}
 }
 
@@ -48,8 +21,13 @@ type InvoicesMySQL struct {
 
 // FindAll returns all invoices from the database.
 func (r *InvoicesMySQL) FindAll() (i []internal.Invoice, err error) {
+	return findAllInvoices(r.db)
+}
+
+// findAllInvoices returns all invoices read by q, the database or a transaction.
+func findAllInvoices(q queryer) (i []internal.Invoice, err error) {
 	// execute the query
-	rows, err := r.db.Query("SELECT `id`, `datetime`, `total`, `customer_id` FROM invoices")
+	rows, err := q.Query("SELECT `id`, `datetime`, `total`, `customer_id` FROM invoices")
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +52,113 @@ func (r *InvoicesMySQL) FindAll() (i []internal.Invoice, err error) {
 	return
 }
 
+// FindById returns an invoice by its id from the database.
+func (r *InvoicesMySQL) FindById(id int) (i internal.Invoice, err error) {
+	// execute the query
+	row := r.db.QueryRow("SELECT `id`, `datetime`, `total`, `customer_id` FROM invoices WHERE `id` = ?", id)
+	err = row.Scan(&i.Id, &i.Datetime, &i.Total, &i.CustomerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryInvoiceNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// queryDiscrepancy is the query that computes the total of the invoices from their sales.
+const queryDiscrepancy = `
+	SELECT
+		i.id,
+		COALESCE(i.total, 0) AS stored_total,
+		ROUND(COALESCE(SUM(s.quantity * p.price), 0), 2) AS computed_total
+	FROM
+		invoices i
+	LEFT JOIN
+		sales s ON i.id = s.invoice_id
+	LEFT JOIN
+		products p ON s.product_id = p.id
+`
+
+// GetDiscrepancies returns the stored and computed totals of all invoices from the database.
+func (r *InvoicesMySQL) GetDiscrepancies() (d []internal.InvoiceDiscrepancy, err error) {
+	// execute the query
+	rows, err := r.db.Query(queryDiscrepancy + `
+	GROUP BY
+		i.id, i.total
+	ORDER BY
+		i.id;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// iterate over the rows
+	for rows.Next() {
+		var ds internal.InvoiceDiscrepancy
+		err := rows.Scan(&ds.InvoiceId, &ds.StoredTotal, &ds.ComputedTotal)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, ds)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// GetDiscrepancyById returns the stored and computed totals of an invoice from the database.
+func (r *InvoicesMySQL) GetDiscrepancyById(id int) (d internal.InvoiceDiscrepancy, err error) {
+	// execute the query
+	row := r.db.QueryRow(queryDiscrepancy+`
+	WHERE
+		i.id = ?
+	GROUP BY
+		i.id, i.total;
+	`, id)
+	err = row.Scan(&d.InvoiceId, &d.StoredTotal, &d.ComputedTotal)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryInvoiceNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// UpdateTotals sets the total of each invoice to the total of its sales in a single transaction.
+// The totals are recomputed inside the transaction, so sales saved since d was read are not lost.
+func (r *InvoicesMySQL) UpdateTotals(d []internal.InvoiceDiscrepancy) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// update the totals
+	for _, ds := range d {
+		err = updateInvoiceTotal(tx, ds.InvoiceId)
+		if err != nil {
+			return
+		}
+	}
+
+	// commit the transaction
+	err = tx.Commit()
+	return
+}
+
 // Save saves the invoice into the database.
 func (r *InvoicesMySQL) Save(i *internal.Invoice) (err error) {
 	// execute the query
@@ -96,3 +181,162 @@ func (r *InvoicesMySQL) Save(i *internal.Invoice) (err error) {
 
 	return
 }
+
+// Checkout saves the invoice and its sales into the database in a single transaction.
+// The total of the invoice is computed from the price of each product sold.
+func (r *InvoicesMySQL) Checkout(i *internal.Invoice, s []internal.Sale) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// check the customer exists
+	var customerId int
+	err = tx.QueryRow("SELECT `id` FROM customers WHERE `id` = ?", (*i).CustomerId).Scan(&customerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryCustomerNotFound
+		}
+		return
+	}
+
+	// insert the invoice
+	res, err := tx.Exec(
+		"INSERT INTO invoices (`datetime`, `total`, `customer_id`) VALUES (?, ?, ?)",
+		(*i).Datetime, 0.0, (*i).CustomerId,
+	)
+	if err != nil {
+		return
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return
+	}
+	(*i).Id = int(id)
+
+	// insert the sales
+	var total float64
+	for ix := range s {
+		// - price of the product
+		var price float64
+		err = tx.QueryRow("SELECT `price` FROM products WHERE `id` = ?", s[ix].ProductId).Scan(&price)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = internal.ErrRepositoryProductNotFound
+			}
+			return
+		}
+		total += price * float64(s[ix].Quantity)
+
+		// - sale
+		s[ix].InvoiceId = (*i).Id
+		res, err = tx.Exec(
+			"INSERT INTO sales (`quantity`, `product_id`, `invoice_id`) VALUES (?, ?, ?)",
+			s[ix].Quantity, s[ix].ProductId, s[ix].InvoiceId,
+		)
+		if err != nil {
+			return
+		}
+		id, err = res.LastInsertId()
+		if err != nil {
+			return
+		}
+		s[ix].Id = int(id)
+	}
+
+	// update the total of the invoice
+	total = math.Round(total*100) / 100
+	_, err = tx.Exec("UPDATE invoices SET `total` = ? WHERE `id` = ?", total, (*i).Id)
+	if err != nil {
+		return
+	}
+	(*i).Total = total
+
+	// commit the transaction
+	err = tx.Commit()
+	return
+}
+
+// Update updates the invoice in the database.
+// The total is not written, it is set to the total of the sales of the invoice.
+func (r *InvoicesMySQL) Update(i *internal.Invoice) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// update the invoice
+	_, err = tx.Exec(
+		"UPDATE invoices SET `datetime` = ?, `customer_id` = ? WHERE `id` = ?",
+		(*i).Datetime, (*i).CustomerId, (*i).Id,
+	)
+	if err != nil {
+		return
+	}
+
+	// update its total
+	err = updateInvoiceTotal(tx, (*i).Id)
+	if err != nil {
+		return
+	}
+	err = tx.QueryRow("SELECT `total` FROM invoices WHERE `id` = ?", (*i).Id).Scan(&(*i).Total)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryInvoiceNotFound
+		}
+		return
+	}
+
+	// commit the transaction
+	err = tx.Commit()
+	return
+}
+
+// Delete deletes the invoice from the database.
+// Its sales are removed by the ON DELETE CASCADE foreign key.
+func (r *InvoicesMySQL) Delete(id int) (err error) {
+	// execute the query
+	res, err := r.db.Exec("DELETE FROM invoices WHERE `id` = ?", id)
+	if err != nil {
+		return
+	}
+
+	// check the invoice existed
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rows == 0 {
+		err = internal.ErrRepositoryInvoiceNotFound
+		return
+	}
+
+	return
+}
+
+// updateInvoiceTotal sets the total of an invoice to the total of its sales.
+func updateInvoiceTotal(tx *sql.Tx, id int) (err error) {
+	_, err = tx.Exec(`
+		UPDATE invoices i
+		SET i.total = (
+			SELECT ROUND(COALESCE(SUM(s.quantity * p.price), 0), 2)
+			FROM sales s
+			JOIN products p ON s.product_id = p.id
+			WHERE s.invoice_id = i.id
+		)
+		WHERE i.id = ?;
+	`, id)
+	return
+}
@@ -18,7 +18,7 @@ func TestInvoicesMySQL_Save(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewInvoicesMySQL(db, nil)
+		repo := repository.NewInvoicesMySQL(db)
 
 		mock.ExpectExec("INSERT INTO invoices").
 			WithArgs(sqlmock.AnyArg(), 100.00, 1).
@@ -48,7 +48,7 @@ func TestInvoicesMySQL_Save(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewInvoicesMySQL(db, nil)
+		repo := repository.NewInvoicesMySQL(db)
 
 		mock.ExpectExec("INSERT INTO invoices").
 			WithArgs(sqlmock.AnyArg(), 100.00, 1).
@@ -79,7 +79,7 @@ func TestInvoicesMySQL_FindAll(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewInvoicesMySQL(db, nil)
+		repo := repository.NewInvoicesMySQL(db)
 
 		rows := sqlmock.NewRows([]string{"id", "datetime", "total", "customer_id"}).
 			AddRow(1, "2023-01-01 12:00:00", 100.00, 1).
@@ -102,7 +102,7 @@ func TestInvoicesMySQL_FindAll(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewInvoicesMySQL(db, nil)
+		repo := repository.NewInvoicesMySQL(db)
 
 		mock.ExpectQuery("SELECT `id`, `datetime`, `total`, `customer_id` FROM invoices").
 			WillReturnError(sql.ErrConnDone)
@@ -113,3 +113,278 @@ func TestInvoicesMySQL_FindAll(t *testing.T) {
 		require.Empty(t, invoices)
 	})
 }
+
+func TestInvoicesMySQL_Checkout(t *testing.T) {
+	t.Run("success - invoice and sales saved", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT `id` FROM customers").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO invoices").
+			WithArgs("2023-01-01 12:00:00", 0.0, 1).
+			WillReturnResult(sqlmock.NewResult(10, 1))
+		mock.ExpectQuery("SELECT `price` FROM products").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(10.50))
+		mock.ExpectExec("INSERT INTO sales").
+			WithArgs(2, 1, 10).
+			WillReturnResult(sqlmock.NewResult(20, 1))
+		mock.ExpectQuery("SELECT `price` FROM products").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(5.25))
+		mock.ExpectExec("INSERT INTO sales").
+			WithArgs(4, 2, 10).
+			WillReturnResult(sqlmock.NewResult(21, 1))
+		mock.ExpectExec("UPDATE invoices SET `total`").
+			WithArgs(42.0, 10).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		invoice := &internal.Invoice{
+			InvoiceAttributes: internal.InvoiceAttributes{
+				Datetime:   "2023-01-01 12:00:00",
+				CustomerId: 1,
+			},
+		}
+		sales := []internal.Sale{
+			{SaleAttributes: internal.SaleAttributes{Quantity: 2, ProductId: 1}},
+			{SaleAttributes: internal.SaleAttributes{Quantity: 4, ProductId: 2}},
+		}
+		err = repo.Checkout(invoice, sales)
+
+		require.NoError(t, err)
+		require.Equal(t, 10, invoice.Id)
+		require.Equal(t, 42.0, invoice.Total)
+		require.Equal(t, 20, sales[0].Id)
+		require.Equal(t, 10, sales[1].InvoiceId)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT `id` FROM customers").
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		invoice := &internal.Invoice{
+			InvoiceAttributes: internal.InvoiceAttributes{CustomerId: 99},
+		}
+		sales := []internal.Sale{
+			{SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 1}},
+		}
+		err = repo.Checkout(invoice, sales)
+
+		require.ErrorIs(t, err, internal.ErrRepositoryCustomerNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - product not found rolls back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT `id` FROM customers").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("INSERT INTO invoices").
+			WillReturnResult(sqlmock.NewResult(10, 1))
+		mock.ExpectQuery("SELECT `price` FROM products").
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		invoice := &internal.Invoice{
+			InvoiceAttributes: internal.InvoiceAttributes{CustomerId: 1},
+		}
+		sales := []internal.Sale{
+			{SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 99}},
+		}
+		err = repo.Checkout(invoice, sales)
+
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInvoicesMySQL_GetDiscrepancies(t *testing.T) {
+	t.Run("success - totals fetched", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		rows := sqlmock.NewRows([]string{"id", "stored_total", "computed_total"}).
+			AddRow(1, 0.0, 150.75).
+			AddRow(2, 200.00, 200.00)
+
+		mock.ExpectQuery(`(?i)SELECT\s+i.id,\s+COALESCE\(i.total, 0\)`).
+			WillReturnRows(rows)
+
+		d, err := repo.GetDiscrepancies()
+
+		require.NoError(t, err)
+		require.Len(t, d, 2)
+		require.Equal(t, 150.75, d[0].ComputedTotal)
+		require.Equal(t, 200.00, d[1].StoredTotal)
+	})
+}
+
+func TestInvoicesMySQL_GetDiscrepancyById(t *testing.T) {
+	t.Run("error - invoice not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectQuery(`(?i)SELECT\s+i.id,\s+COALESCE\(i.total, 0\)`).
+			WithArgs(99).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "stored_total", "computed_total"}))
+
+		_, err = repo.GetDiscrepancyById(99)
+
+		require.ErrorIs(t, err, internal.ErrRepositoryInvoiceNotFound)
+	})
+}
+
+func TestInvoicesMySQL_UpdateTotals(t *testing.T) {
+	t.Run("success - totals updated in a transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.UpdateTotals([]internal.InvoiceDiscrepancy{
+			{InvoiceId: 1, ComputedTotal: 150.75},
+			{InvoiceId: 3, ComputedTotal: 20.00},
+		})
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update failed rolls back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(1).
+			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repo.UpdateTotals([]internal.InvoiceDiscrepancy{{InvoiceId: 1, ComputedTotal: 150.75}})
+
+		require.ErrorIs(t, err, sql.ErrConnDone)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInvoicesMySQL_Update(t *testing.T) {
+	t.Run("success - invoice updated with the total of its sales", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE invoices SET `datetime` = \\?, `customer_id` = \\? WHERE `id` = \\?").
+			WithArgs("2023-01-02 12:00:00", 2, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT `total` FROM invoices").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(30.5))
+		mock.ExpectCommit()
+
+		invoice := &internal.Invoice{
+			Id: 1,
+			InvoiceAttributes: internal.InvoiceAttributes{
+				Datetime:   "2023-01-02 12:00:00",
+				Total:      999,
+				CustomerId: 2,
+			},
+		}
+		err = repo.Update(invoice)
+
+		require.NoError(t, err)
+		require.Equal(t, 30.5, invoice.Total)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invoice not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewInvoicesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE invoices SET `datetime`").
+			WithArgs("2023-01-02 12:00:00", 2, 99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT `total` FROM invoices").
+			WithArgs(99).
+			WillReturnRows(sqlmock.NewRows([]string{"total"}))
+		mock.ExpectRollback()
+
+		err = repo.Update(&internal.Invoice{Id: 99, InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2023-01-02 12:00:00", CustomerId: 2}})
+
+		require.ErrorIs(t, err, internal.ErrRepositoryInvoiceNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -2,41 +2,14 @@ package repository
 
 import (
 	"database/sql"
-	"log"
+	"errors"
+	"strings"
 
 	"app/internal"
 )
 
 // NewProductsMySQL creates new mysql repository for product entity.
-func NewProductsMySQL(db *sql.DB, storage internal.StorageProduct) *ProductsMySQL {
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM products").Scan(&count)
-	if err != nil && storage != nil {
-		log.Printf("Error checking products table: %v", err)
-		return &ProductsMySQL{db}
-	}
-
-	if count > 0 {
-		return &ProductsMySQL{db}
-	}
-
-	if storage != nil {
-		products, err := storage.FindAll()
-		if err == nil {
-			for _, prod := range products {
-				_, err := db.Exec(
-					"INSERT INTO products (`description`, `price`) VALUES (?, ?)",
-					prod.Description, prod.Price,
-				)
-				if err != nil {
-					log.Printf("Error inserting product %v: %v", prod, err)
-				}
-			}
-		} else {
-			log.Printf("Error fetching products from storage: %v", err)
-		}
-	}
-
+func NewProductsMySQL(db *sql.DB) *ProductsMySQL {
 	return &ProductsMySQL{db}
 }
 
@@ -48,8 +21,13 @@ type ProductsMySQL struct {
 
 // FindAll returns all products from the database.
 func (r *ProductsMySQL) FindAll() (p []internal.Product, err error) {
+	return findAllProducts(r.db)
+}
+
+// findAllProducts returns all products read by q, the database or a transaction.
+func findAllProducts(q queryer) (p []internal.Product, err error) {
 	// execute the query
-	rows, err := r.db.Query("SELECT `id`, `description`, `price` FROM products")
+	rows, err := q.Query("SELECT `id`, `description`, `price` FROM products")
 	if err != nil {
 		return nil, err
 	}
@@ -74,21 +52,73 @@ func (r *ProductsMySQL) FindAll() (p []internal.Product, err error) {
 	return
 }
 
-func (r *ProductsMySQL) GetBestSelling() (p []internal.ProductBestSelling, err error) {
-	rows, err := r.db.Query(`
+// FindById returns a product by its id from the database.
+func (r *ProductsMySQL) FindById(id int) (p internal.Product, err error) {
+	// execute the query
+	row := r.db.QueryRow("SELECT `id`, `description`, `price` FROM products WHERE `id` = ?", id)
+	err = row.Scan(&p.Id, &p.Description, &p.Price)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetBestSelling returns the best-selling products from the database.
+// The share of each product is computed over the revenue of all products in the period.
+func (r *ProductsMySQL) GetBestSelling(f internal.ProductBestSellingFilter) (p []internal.ProductBestSelling, err error) {
+	// build the query
+	var order string
+	switch f.OrderBy {
+	case internal.ProductBestSellingOrderQuantity:
+		order = "units DESC, revenue DESC"
+	case internal.ProductBestSellingOrderRevenue:
+		order = "revenue DESC, units DESC"
+	default:
+		return nil, internal.ErrRepositoryProductOrderInvalid
+	}
+	var join, where string
+	var conditions []string
+	var args []any
+	if !f.From.IsZero() {
+		conditions = append(conditions, "i.datetime >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "i.datetime <= ?")
+		args = append(args, f.To)
+	}
+	if len(conditions) > 0 {
+		join = "JOIN invoices i ON s.invoice_id = i.id"
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	query := `
 		SELECT 
+			p.id,
 			p.description, 
-			SUM(s.quantity) AS total_sold
+			SUM(s.quantity) AS units,
+			ROUND(SUM(s.quantity * p.price), 2) AS revenue,
+			COALESCE(ROUND(SUM(s.quantity * p.price) * 100 / SUM(SUM(s.quantity * p.price)) OVER (), 2), 0) AS share
 		FROM 
 			products p
 		JOIN 
 			sales s ON p.id = s.product_id
+		` + join + `
+		` + where + `
 		GROUP BY 
-			p.id
+			p.id, p.description
 		ORDER BY 
-			total_sold DESC
-		LIMIT 5;
-	`)
+			` + order
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	// execute the query
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +126,7 @@ func (r *ProductsMySQL) GetBestSelling() (p []internal.ProductBestSelling, err e
 
 	for rows.Next() {
 		var bestSelling internal.ProductBestSelling
-		err := rows.Scan(&bestSelling.Description, &bestSelling.Total)
+		err := rows.Scan(&bestSelling.Id, &bestSelling.Description, &bestSelling.Units, &bestSelling.Revenue, &bestSelling.Share)
 		if err != nil {
 			return nil, err
 		}
@@ -134,3 +164,110 @@ func (r *ProductsMySQL) Save(p *internal.Product) (err error) {
 
 	return
 }
+
+// Update updates the product in the database, along with the total of the invoices that contain it.
+func (r *ProductsMySQL) Update(p *internal.Product) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// update the product
+	_, err = tx.Exec(
+		"UPDATE products SET `description` = ?, `price` = ? WHERE `id` = ?",
+		(*p).Description, (*p).Price, (*p).Id,
+	)
+	if err != nil {
+		return
+	}
+
+	// update the total of the invoices that contain it
+	invoiceIds, err := invoiceIdsOfProduct(tx, (*p).Id)
+	if err != nil {
+		return
+	}
+	for _, invoiceId := range invoiceIds {
+		err = updateInvoiceTotal(tx, invoiceId)
+		if err != nil {
+			return
+		}
+	}
+
+	// commit the transaction
+	err = tx.Commit()
+	return
+}
+
+// Delete deletes the product from the database.
+// Its sales are removed by the ON DELETE CASCADE foreign key, so the total of the
+// invoices that contained it is updated in the same transaction.
+func (r *ProductsMySQL) Delete(id int) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// invoices affected by the cascade
+	invoiceIds, err := invoiceIdsOfProduct(tx, id)
+	if err != nil {
+		return
+	}
+
+	// delete the product
+	res, err := tx.Exec("DELETE FROM products WHERE `id` = ?", id)
+	if err != nil {
+		return
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affected == 0 {
+		err = internal.ErrRepositoryProductNotFound
+		return
+	}
+
+	// update the total of the affected invoices
+	for _, invoiceId := range invoiceIds {
+		err = updateInvoiceTotal(tx, invoiceId)
+		if err != nil {
+			return
+		}
+	}
+
+	// commit the transaction
+	err = tx.Commit()
+	return
+}
+
+// invoiceIdsOfProduct returns the ids of the invoices with a sale of the product.
+func invoiceIdsOfProduct(tx *sql.Tx, id int) (invoiceIds []int, err error) {
+	rows, err := tx.Query("SELECT DISTINCT `invoice_id` FROM sales WHERE `product_id` = ?", id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var invoiceId int
+		err = rows.Scan(&invoiceId)
+		if err != nil {
+			return
+		}
+		invoiceIds = append(invoiceIds, invoiceId)
+	}
+	err = rows.Err()
+	return
+}
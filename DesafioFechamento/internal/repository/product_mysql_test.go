@@ -3,7 +3,9 @@ package repository_test
 import (
 	"app/internal"
 	"app/internal/repository"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/require"
@@ -17,7 +19,7 @@ func TestProductsMySQL_Save(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewProductsMySQL(db, nil)
+		repo := repository.NewProductsMySQL(db)
 
 		mock.ExpectExec("INSERT INTO products").
 			WithArgs("New Product", 150.00).
@@ -49,7 +51,7 @@ func TestProductsMySQL_FindAll(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewProductsMySQL(db, nil)
+		repo := repository.NewProductsMySQL(db)
 
 		rows := sqlmock.NewRows([]string{"id", "description", "price"}).
 			AddRow(1, "Product 1", 100.00).
@@ -76,20 +78,168 @@ func TestProductsMySQL_GetBestSelling(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewProductsMySQL(db, nil)
+		repo := repository.NewProductsMySQL(db)
 
-		rows := sqlmock.NewRows([]string{"description", "total_sold"}).
-			AddRow("Product 1", 100).
-			AddRow("Product 2", 150)
+		rows := sqlmock.NewRows([]string{"id", "description", "units", "revenue", "share"}).
+			AddRow(1, "Product 1", 100, 1000.00, 40.00).
+			AddRow(2, "Product 2", 150, 1500.00, 60.00)
 
-		mock.ExpectQuery("SELECT p.description, SUM\\(s.quantity\\)").
+		mock.ExpectQuery("SELECT p.id, p.description, SUM\\(s.quantity\\) AS units").
+			WithArgs(5).
 			WillReturnRows(rows)
 
-		bestSellingProducts, err := repo.GetBestSelling()
+		bestSellingProducts, err := repo.GetBestSelling(internal.ProductBestSellingFilter{
+			Limit:   5,
+			OrderBy: internal.ProductBestSellingOrderQuantity,
+		})
 
 		require.NoError(t, err)
 		require.Len(t, bestSellingProducts, 2)
 		require.Equal(t, bestSellingProducts[0].Description, "Product 1")
-		require.Equal(t, bestSellingProducts[1].Total, 150)
+		require.Equal(t, bestSellingProducts[1].Units, 150)
+		require.Equal(t, bestSellingProducts[1].Share, 60.00)
+	})
+
+	t.Run("success - ranked by revenue in a date range", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewProductsMySQL(db)
+
+		from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"id", "description", "units", "revenue", "share"}).
+			AddRow(3, "Product 3", 10, 3000.00, 100.00)
+
+		mock.ExpectQuery("JOIN invoices i ON s.invoice_id = i.id WHERE i.datetime >= \\? GROUP BY p.id, p.description ORDER BY revenue DESC, units DESC").
+			WithArgs(from).
+			WillReturnRows(rows)
+
+		bestSellingProducts, err := repo.GetBestSelling(internal.ProductBestSellingFilter{
+			OrderBy: internal.ProductBestSellingOrderRevenue,
+			From:    from,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, bestSellingProducts, 1)
+		require.Equal(t, bestSellingProducts[0].Id, 3)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductsMySQL_Update(t *testing.T) {
+	t.Run("success - product updated and invoices totals updated", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewProductsMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE products").
+			WithArgs("Apple", 2.5, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT DISTINCT `invoice_id` FROM sales").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"invoice_id"}).AddRow(3).AddRow(7))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.Update(&internal.Product{Id: 1, ProductAttributes: internal.ProductAttributes{Description: "Apple", Price: 2.5}})
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invoice total not updated rolls back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewProductsMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE products").
+			WithArgs("Apple", 2.5, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT DISTINCT `invoice_id` FROM sales").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"invoice_id"}).AddRow(3))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(3).
+			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repo.Update(&internal.Product{Id: 1, ProductAttributes: internal.ProductAttributes{Description: "Apple", Price: 2.5}})
+
+		require.ErrorIs(t, err, sql.ErrConnDone)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductsMySQL_Delete(t *testing.T) {
+	t.Run("success - product deleted and invoices totals updated", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewProductsMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT DISTINCT `invoice_id` FROM sales").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"invoice_id"}).AddRow(3).AddRow(7))
+		mock.ExpectExec("DELETE FROM products").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.Delete(1)
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewProductsMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT DISTINCT `invoice_id` FROM sales").
+			WithArgs(99).
+			WillReturnRows(sqlmock.NewRows([]string{"invoice_id"}))
+		mock.ExpectExec("DELETE FROM products").
+			WithArgs(99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err = repo.Delete(99)
+
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
 	})
 }
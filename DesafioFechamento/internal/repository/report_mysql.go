@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"app/internal"
+)
+
+// NewReportsMySQL creates new mysql repository for reports.
+func NewReportsMySQL(db *sql.DB) *ReportsMySQL {
+	return &ReportsMySQL{db}
+}
+
+// ReportsMySQL is the MySQL repository implementation for reports.
+type ReportsMySQL struct {
+	// db is the database connection.
+	db *sql.DB
+}
+
+// salesReportPeriods are the expressions of the first day of each interval of the sales report.
+var salesReportPeriods = map[internal.SalesReportInterval]string{
+	internal.SalesReportIntervalDay:   "DATE_FORMAT(i.datetime, '%Y-%m-%d')",
+	internal.SalesReportIntervalWeek:  "DATE_FORMAT(DATE_SUB(DATE(i.datetime), INTERVAL WEEKDAY(i.datetime) DAY), '%Y-%m-%d')",
+	internal.SalesReportIntervalMonth: "DATE_FORMAT(i.datetime, '%Y-%m-01')",
+}
+
+// GetSales returns the sales bucketed by period from the database.
+func (r *ReportsMySQL) GetSales(f internal.SalesReportFilter) (b []internal.SalesReportBucket, err error) {
+	// build the query
+	period, ok := salesReportPeriods[f.Interval]
+	if !ok {
+		return nil, internal.ErrRepositoryReportInvalid
+	}
+	var columns, join, group string
+	switch f.GroupBy {
+	case internal.SalesReportGroupNone:
+	case internal.SalesReportGroupProduct:
+		columns = "p.id, p.description,"
+		group = ", p.id, p.description"
+	case internal.SalesReportGroupCondition:
+		columns = "c.condition,"
+		join = "JOIN customers c ON i.customer_id = c.id"
+		group = ", c.condition"
+	default:
+		return nil, internal.ErrRepositoryReportInvalid
+	}
+	var conditions []string
+	var args []any
+	if !f.From.IsZero() {
+		conditions = append(conditions, "i.datetime >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "i.datetime <= ?")
+		args = append(args, f.To)
+	}
+	var where string
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// execute the query
+	rows, err := r.db.Query(`
+		SELECT
+			`+period+` AS period,
+			`+columns+`
+			ROUND(SUM(s.quantity * p.price), 2) AS revenue,
+			SUM(s.quantity) AS units,
+			COUNT(DISTINCT i.id) AS invoices
+		FROM
+			invoices i
+		JOIN
+			sales s ON i.id = s.invoice_id
+		JOIN
+			products p ON s.product_id = p.id
+		`+join+`
+		`+where+`
+		GROUP BY
+			period`+group+`
+		ORDER BY
+			period`+group+`;
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// iterate over the rows
+	for rows.Next() {
+		var bk internal.SalesReportBucket
+		dest := []any{&bk.Period}
+		switch f.GroupBy {
+		case internal.SalesReportGroupProduct:
+			bk.ProductId = new(int)
+			dest = append(dest, bk.ProductId, &bk.Description)
+		case internal.SalesReportGroupCondition:
+			bk.Condition = new(int)
+			dest = append(dest, bk.Condition)
+		}
+		dest = append(dest, &bk.Revenue, &bk.Units, &bk.Invoices)
+		// scan the row into the bucket
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, bk)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportsMySQL_GetSales(t *testing.T) {
+	t.Run("success - sales by month", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewReportsMySQL(db)
+
+		rows := sqlmock.NewRows([]string{"period", "revenue", "units", "invoices"}).
+			AddRow("2022-01-01", 1500.50, 30, 4).
+			AddRow("2022-02-01", 250.00, 5, 1)
+
+		mock.ExpectQuery(`(?i)SELECT DATE_FORMAT\(i.datetime, '%Y-%m-01'\) AS period, ROUND`).
+			WillReturnRows(rows)
+
+		buckets, err := repo.GetSales(internal.SalesReportFilter{Interval: internal.SalesReportIntervalMonth})
+
+		require.NoError(t, err)
+		require.Len(t, buckets, 2)
+		require.Equal(t, "2022-01-01", buckets[0].Period)
+		require.Equal(t, 1500.50, buckets[0].Revenue)
+		require.Equal(t, 30, buckets[0].Units)
+		require.Equal(t, 4, buckets[0].Invoices)
+		require.Nil(t, buckets[0].ProductId)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - sales by week and product in a range", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewReportsMySQL(db)
+
+		from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2022, 1, 31, 23, 59, 59, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"period", "id", "description", "revenue", "units", "invoices"}).
+			AddRow("2022-01-03", 7, "Product 7", 70.00, 2, 1)
+
+		mock.ExpectQuery(`(?i)WEEKDAY\(i.datetime\).*p.id, p.description,.*WHERE i.datetime >= \? AND i.datetime <= \? GROUP BY period, p.id, p.description`).
+			WithArgs(from, to).
+			WillReturnRows(rows)
+
+		buckets, err := repo.GetSales(internal.SalesReportFilter{
+			Interval: internal.SalesReportIntervalWeek,
+			GroupBy:  internal.SalesReportGroupProduct,
+			From:     from,
+			To:       to,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		require.Equal(t, 7, *buckets[0].ProductId)
+		require.Equal(t, "Product 7", buckets[0].Description)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - invalid interval", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewReportsMySQL(db)
+
+		_, err = repo.GetSales(internal.SalesReportFilter{Interval: "year"})
+
+		require.ErrorIs(t, err, internal.ErrRepositoryReportInvalid)
+	})
+}
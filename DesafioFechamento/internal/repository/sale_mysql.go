@@ -2,41 +2,13 @@ package repository
 
 import (
 	"database/sql"
-	"log"
+	"errors"
 
 	"app/internal"
 )
 
 // NewSalesMySQL creates new mysql repository for sale entity.
-func NewSalesMySQL(db *sql.DB, storage internal.StorageSale) *SalesMySQL {
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM sales").Scan(&count)
-	if err != nil && storage != nil {
-		log.Printf("Error checking sales table: %v", err)
-		return &SalesMySQL{db}
-	}
-
-	if count > 0 {
-		return &SalesMySQL{db}
-	}
-
-	if storage != nil {
-		sales, err := storage.FindAll()
-		if err == nil {
-			for _, sale := range sales {
-				_, err := db.Exec(
-					"INSERT INTO sales (`quantity`, `product_id`, `invoice_id`) VALUES (?, ?, ?)",
-					sale.Quantity, sale.ProductId, sale.InvoiceId,
-				)
-				if err != nil {
-					log.Printf("Error inserting sale %v: %v", sale, err)
-				}
-			}
-		} else {
-			log.Printf("Error fetching sales from storage: %v", err)
-		}
-	}
-
+func NewSalesMySQL(db *sql.DB) *SalesMySQL {
 	return &SalesMySQL{db}
 }
 
@@ -48,8 +20,13 @@ type SalesMySQL struct {
 
 // FindAll returns all sales from the database.
 func (r *SalesMySQL) FindAll() (s []internal.Sale, err error) {
+	return findAllSales(r.db)
+}
+
+// findAllSales returns all sales read by q, the database or a transaction.
+func findAllSales(q queryer) (s []internal.Sale, err error) {
 	// execute the query
-	rows, err := r.db.Query("SELECT `id`, `quantity`, `product_id`, `invoice_id` FROM sales")
+	rows, err := q.Query("SELECT `id`, `quantity`, `product_id`, `invoice_id` FROM sales")
 	if err != nil {
 		return nil, err
 	}
@@ -74,21 +51,59 @@ func (r *SalesMySQL) FindAll() (s []internal.Sale, err error) {
 	return
 }
 
-// Save saves the sale into the database.
-func (r *SalesMySQL) Save(s *internal.Sale) (err error) {
+// FindById returns a sale by its id from the database.
+func (r *SalesMySQL) FindById(id int) (s internal.Sale, err error) {
 	// execute the query
-	res, err := r.db.Exec(
+	row := r.db.QueryRow("SELECT `id`, `quantity`, `product_id`, `invoice_id` FROM sales WHERE `id` = ?", id)
+	err = row.Scan(&s.Id, &s.Quantity, &s.ProductId, &s.InvoiceId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositorySaleNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// Save saves the sale into the database, along with the total of its invoice.
+func (r *SalesMySQL) Save(s *internal.Sale) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// insert the sale
+	res, err := tx.Exec(
 		"INSERT INTO sales (`quantity`, `product_id`, `invoice_id`) VALUES (?, ?, ?)",
 		(*s).Quantity, (*s).ProductId, (*s).InvoiceId,
 	)
 	if err != nil {
-		return err
+		return
 	}
 
 	// get the last inserted id
 	id, err := res.LastInsertId()
 	if err != nil {
-		return err
+		return
+	}
+
+	// update the total of the invoice
+	err = updateInvoiceTotal(tx, (*s).InvoiceId)
+	if err != nil {
+		return
+	}
+
+	// commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return
 	}
 
 	// set the id
@@ -96,3 +111,92 @@ func (r *SalesMySQL) Save(s *internal.Sale) (err error) {
 
 	return
 }
+
+// Update updates the sale in the database, along with the total of the invoices involved.
+func (r *SalesMySQL) Update(s *internal.Sale) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// current invoice of the sale
+	var oldInvoiceId int
+	err = tx.QueryRow("SELECT `invoice_id` FROM sales WHERE `id` = ? FOR UPDATE", (*s).Id).Scan(&oldInvoiceId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositorySaleNotFound
+		}
+		return
+	}
+
+	// update the sale
+	_, err = tx.Exec(
+		"UPDATE sales SET `quantity` = ?, `product_id` = ?, `invoice_id` = ? WHERE `id` = ?",
+		(*s).Quantity, (*s).ProductId, (*s).InvoiceId, (*s).Id,
+	)
+	if err != nil {
+		return
+	}
+
+	// update the total of the invoices
+	err = updateInvoiceTotal(tx, (*s).InvoiceId)
+	if err != nil {
+		return
+	}
+	if oldInvoiceId != (*s).InvoiceId {
+		err = updateInvoiceTotal(tx, oldInvoiceId)
+		if err != nil {
+			return
+		}
+	}
+
+	// commit the transaction
+	err = tx.Commit()
+	return
+}
+
+// Delete deletes the sale from the database, along with the total of its invoice.
+func (r *SalesMySQL) Delete(id int) (err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// invoice of the sale
+	var invoiceId int
+	err = tx.QueryRow("SELECT `invoice_id` FROM sales WHERE `id` = ? FOR UPDATE", id).Scan(&invoiceId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositorySaleNotFound
+		}
+		return
+	}
+
+	// delete the sale
+	_, err = tx.Exec("DELETE FROM sales WHERE `id` = ?", id)
+	if err != nil {
+		return
+	}
+
+	// update the total of the invoice
+	err = updateInvoiceTotal(tx, invoiceId)
+	if err != nil {
+		return
+	}
+
+	// commit the transaction
+	err = tx.Commit()
+	return
+}
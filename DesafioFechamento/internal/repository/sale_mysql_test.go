@@ -11,18 +11,23 @@ import (
 )
 
 func TestSalesMySQL_Save(t *testing.T) {
-	t.Run("success - sale saved", func(t *testing.T) {
+	t.Run("success - sale saved and invoice total updated", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
 		}
 		defer db.Close()
 
-		repo := repository.NewSalesMySQL(db, nil)
+		repo := repository.NewSalesMySQL(db)
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO sales").
 			WithArgs(10, 1, 1).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
 		sale := &internal.Sale{
 			SaleAttributes: internal.SaleAttributes{
@@ -48,11 +53,13 @@ func TestSalesMySQL_Save(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewSalesMySQL(db, nil)
+		repo := repository.NewSalesMySQL(db)
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO sales").
 			WithArgs(10, 1, 1).
 			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
 
 		sale := &internal.Sale{
 			SaleAttributes: internal.SaleAttributes{
@@ -79,7 +86,7 @@ func TestSalesMySQL_FindAll(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewSalesMySQL(db, nil)
+		repo := repository.NewSalesMySQL(db)
 
 		rows := sqlmock.NewRows([]string{"id", "quantity", "product_id", "invoice_id"}).
 			AddRow(1, 10, 1, 1).
@@ -103,7 +110,7 @@ func TestSalesMySQL_FindAll(t *testing.T) {
 		}
 		defer db.Close()
 
-		repo := repository.NewSalesMySQL(db, nil)
+		repo := repository.NewSalesMySQL(db)
 
 		mock.ExpectQuery("SELECT `id`, `quantity`, `product_id`, `invoice_id` FROM sales").
 			WillReturnError(sql.ErrConnDone)
@@ -114,3 +121,93 @@ func TestSalesMySQL_FindAll(t *testing.T) {
 		require.Empty(t, sales)
 	})
 }
+
+func TestSalesMySQL_Update(t *testing.T) {
+	t.Run("success - sale moved to another invoice", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewSalesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT `invoice_id` FROM sales").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"invoice_id"}).AddRow(1))
+		mock.ExpectExec("UPDATE sales").
+			WithArgs(5, 2, 3, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		sale := &internal.Sale{
+			Id: 1,
+			SaleAttributes: internal.SaleAttributes{
+				Quantity:  5,
+				ProductId: 2,
+				InvoiceId: 3,
+			},
+		}
+		err = repo.Update(sale)
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSalesMySQL_Delete(t *testing.T) {
+	t.Run("success - sale deleted and invoice total updated", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewSalesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT `invoice_id` FROM sales").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"invoice_id"}).AddRow(4))
+		mock.ExpectExec("DELETE FROM sales").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE invoices i").
+			WithArgs(4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.Delete(1)
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - sale not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' occurred when opening a mock database connection: %s", err, err)
+		}
+		defer db.Close()
+
+		repo := repository.NewSalesMySQL(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT `invoice_id` FROM sales").
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err = repo.Delete(99)
+
+		require.ErrorIs(t, err, internal.ErrRepositorySaleNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+
+	"app/internal"
+)
+
+// NewSeedMySQL creates new mysql repository for seeding the database.
+func NewSeedMySQL(db *sql.DB) *SeedMySQL {
+	return &SeedMySQL{db}
+}
+
+// SeedMySQL is the MySQL repository implementation for seeding the database.
+type SeedMySQL struct {
+	// db is the database connection.
+	db *sql.DB
+}
+
+const (
+	// querySeedCustomer upserts a customer keeping its id.
+	querySeedCustomer = "INSERT INTO customers (`id`, `first_name`, `last_name`, `condition`) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `first_name` = VALUES(`first_name`), `last_name` = VALUES(`last_name`), `condition` = VALUES(`condition`)"
+	// querySeedProduct upserts a product keeping its id.
+	querySeedProduct = "INSERT INTO products (`id`, `description`, `price`) VALUES (?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `description` = VALUES(`description`), `price` = VALUES(`price`)"
+	// querySeedInvoice upserts an invoice keeping its id.
+	querySeedInvoice = "INSERT INTO invoices (`id`, `datetime`, `customer_id`, `total`) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `datetime` = VALUES(`datetime`), `customer_id` = VALUES(`customer_id`), `total` = VALUES(`total`)"
+	// querySeedSale upserts a sale keeping its id.
+	querySeedSale = "INSERT INTO sales (`id`, `quantity`, `invoice_id`, `product_id`) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `quantity` = VALUES(`quantity`), `invoice_id` = VALUES(`invoice_id`), `product_id` = VALUES(`product_id`)"
+)
+
+// Seed upserts the data into the database in a single transaction, keeping the ids.
+// Customers, products, invoices and sales are loaded in this order so foreign keys are satisfied.
+func (r *SeedMySQL) Seed(d internal.SeedData, dryRun bool) (s internal.SeedSummary, err error) {
+	// start the transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil || dryRun {
+			tx.Rollback()
+		}
+	}()
+
+	// customers
+	args := make([][]any, len(d.Customers))
+	for ix, c := range d.Customers {
+		args[ix] = []any{c.Id, c.FirstName, c.LastName, c.Condition}
+	}
+	s.Customers, err = seedRows(tx, querySeedCustomer, args)
+	if err != nil {
+		return
+	}
+
+	// products
+	args = make([][]any, len(d.Products))
+	for ix, p := range d.Products {
+		args[ix] = []any{p.Id, p.Description, p.Price}
+	}
+	s.Products, err = seedRows(tx, querySeedProduct, args)
+	if err != nil {
+		return
+	}
+
+	// invoices
+	args = make([][]any, len(d.Invoices))
+	for ix, i := range d.Invoices {
+		args[ix] = []any{i.Id, i.Datetime, i.CustomerId, i.Total}
+	}
+	s.Invoices, err = seedRows(tx, querySeedInvoice, args)
+	if err != nil {
+		return
+	}
+
+	// sales
+	args = make([][]any, len(d.Sales))
+	for ix, sl := range d.Sales {
+		args[ix] = []any{sl.Id, sl.Quantity, sl.InvoiceId, sl.ProductId}
+	}
+	s.Sales, err = seedRows(tx, querySeedSale, args)
+	if err != nil {
+		return
+	}
+
+	// commit the transaction
+	if dryRun {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// seedRows executes the upsert query once per row and counts the result of each one.
+// MySQL reports 1 affected row for an insert, 2 for an update and 0 when nothing changed.
+func seedRows(tx *sql.Tx, query string, args [][]any) (c internal.SeedCount, err error) {
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	for _, a := range args {
+		var res sql.Result
+		res, err = stmt.Exec(a...)
+		if err != nil {
+			return
+		}
+		var rows int64
+		rows, err = res.RowsAffected()
+		if err != nil {
+			return
+		}
+		switch rows {
+		case 0:
+			c.Unchanged++
+		case 1:
+			c.Inserted++
+		default:
+			c.Updated++
+		}
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package repository_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"app/internal"
+	"app/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedMySQL_Seed(t *testing.T) {
+	data := internal.SeedData{
+		Customers: []internal.Customer{
+			{Id: 1, CustomerAttributes: internal.CustomerAttributes{FirstName: "John", LastName: "Doe", Condition: 1}},
+			{Id: 2, CustomerAttributes: internal.CustomerAttributes{FirstName: "Jane", LastName: "Doe", Condition: 0}},
+		},
+		Products: []internal.Product{
+			{Id: 7, ProductAttributes: internal.ProductAttributes{Description: "Apple", Price: 1.5}},
+		},
+		Invoices: []internal.Invoice{
+			{Id: 3, InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-01", CustomerId: 2}},
+		},
+		Sales: []internal.Sale{
+			{Id: 4, SaleAttributes: internal.SaleAttributes{Quantity: 2, ProductId: 7, InvoiceId: 3}},
+		},
+	}
+
+	t.Run("success - rows upserted keeping ids", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewSeedMySQL(db)
+
+		mock.ExpectBegin()
+		prep := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO customers (`id`, `first_name`, `last_name`, `condition`)"))
+		prep.ExpectExec().WithArgs(1, "John", "Doe", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+		prep.ExpectExec().WithArgs(2, "Jane", "Doe", 0).WillReturnResult(sqlmock.NewResult(2, 0))
+		prep = mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO products (`id`, `description`, `price`)"))
+		prep.ExpectExec().WithArgs(7, "Apple", 1.5).WillReturnResult(sqlmock.NewResult(7, 2))
+		prep = mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO invoices (`id`, `datetime`, `customer_id`, `total`)"))
+		prep.ExpectExec().WithArgs(3, "2022-01-01", 2, 0.0).WillReturnResult(sqlmock.NewResult(3, 1))
+		prep = mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO sales (`id`, `quantity`, `invoice_id`, `product_id`)"))
+		prep.ExpectExec().WithArgs(4, 2, 3, 7).WillReturnResult(sqlmock.NewResult(4, 1))
+		mock.ExpectCommit()
+
+		summary, err := repo.Seed(data, false)
+
+		require.NoError(t, err)
+		require.Equal(t, internal.SeedCount{Inserted: 1, Unchanged: 1}, summary.Customers)
+		require.Equal(t, internal.SeedCount{Updated: 1}, summary.Products)
+		require.Equal(t, internal.SeedCount{Inserted: 1}, summary.Invoices)
+		require.Equal(t, internal.SeedCount{Inserted: 1}, summary.Sales)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - dry run rolled back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewSeedMySQL(db)
+
+		mock.ExpectBegin()
+		for _, table := range []string{"customers", "products", "invoices", "sales"} {
+			prep := mock.ExpectPrepare("INSERT INTO " + table)
+			rows := 1
+			if table == "customers" {
+				rows = 2
+			}
+			for ix := 0; ix < rows; ix++ {
+				prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+		}
+		mock.ExpectRollback()
+
+		summary, err := repo.Seed(data, true)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, summary.Customers.Inserted)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - insert fails and transaction is rolled back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewSeedMySQL(db)
+
+		errInsert := errors.New("foreign key constraint fails")
+		mock.ExpectBegin()
+		prep := mock.ExpectPrepare("INSERT INTO customers")
+		prep.ExpectExec().WillReturnError(errInsert)
+		mock.ExpectRollback()
+
+		_, err = repo.Seed(data, false)
+
+		require.ErrorIs(t, err, errInsert)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
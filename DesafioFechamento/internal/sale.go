@@ -16,4 +16,15 @@ type Sale struct {
 	Id int
 	// SaleAttributes is the attributes of the sale.
 	SaleAttributes
-}
\ No newline at end of file
+}
+
+// SalePatch is the struct that represents the fields of a sale to be patched.
+// A nil field is left untouched.
+type SalePatch struct {
+	// Quantity is the quantity of the sale.
+	Quantity *int
+	// ProductId is the product id of the sale.
+	ProductId *int
+	// InvoiceId is the invoice id of the sale.
+	InvoiceId *int
+}
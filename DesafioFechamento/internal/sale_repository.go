@@ -1,9 +1,22 @@
 package internal
 
+import "errors"
+
+var (
+	// ErrRepositorySaleNotFound is returned when a sale is not found.
+	ErrRepositorySaleNotFound = errors.New("repository: sale not found")
+)
+
 // RepositorySale is the interface that wraps the basic Sale methods.
 type RepositorySale interface {
 	// FindAll returns all sales.
 	FindAll() (s []Sale, err error)
-	// Save saves a sale.
+	// FindById returns a sale by its id.
+	FindById(id int) (s Sale, err error)
+	// Save saves a sale, updating the total of its invoice.
 	Save(s *Sale) (err error)
-}
\ No newline at end of file
+	// Update updates a sale, updating the total of the invoices involved.
+	Update(s *Sale) (err error)
+	// Delete deletes a sale, updating the total of its invoice.
+	Delete(id int) (err error)
+}
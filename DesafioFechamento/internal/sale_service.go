@@ -4,6 +4,14 @@ package internal
 type ServiceSale interface {
 	// FindAll returns all sales.
 	FindAll() (s []Sale, err error)
+	// FindById returns a sale by its id.
+	FindById(id int) (s Sale, err error)
 	// Save saves a sale.
 	Save(s *Sale) (err error)
-}
\ No newline at end of file
+	// Update updates a sale.
+	Update(s *Sale) (err error)
+	// Patch updates the given fields of a sale.
+	Patch(id int, p SalePatch) (s Sale, err error)
+	// Delete deletes a sale.
+	Delete(id int) (err error)
+}
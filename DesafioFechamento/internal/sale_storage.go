@@ -4,4 +4,6 @@ package internal
 type StorageSale interface {
 	// FindAll returns all sales.
 	FindAll() (s []Sale, err error)
+	// SaveAll replaces the stored sales with s.
+	SaveAll(s []Sale) (err error)
 }
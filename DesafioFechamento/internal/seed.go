@@ -0,0 +1,36 @@
+package internal
+
+// SeedData is the struct that represents the entities to be loaded into the database.
+// Ids are preserved, so references between the entities must point at ids of the same data.
+type SeedData struct {
+	// Customers are the customers to be loaded.
+	Customers []Customer
+	// Products are the products to be loaded.
+	Products []Product
+	// Invoices are the invoices to be loaded.
+	Invoices []Invoice
+	// Sales are the sales to be loaded.
+	Sales []Sale
+}
+
+// SeedCount is the struct that represents what happened to the rows of an entity during a seed.
+type SeedCount struct {
+	// Inserted is the number of rows that did not exist.
+	Inserted int
+	// Updated is the number of rows that existed with different values.
+	Updated int
+	// Unchanged is the number of rows that already existed with the same values.
+	Unchanged int
+}
+
+// SeedSummary is the struct that represents the result of a seed.
+type SeedSummary struct {
+	// Customers is the count of the customers.
+	Customers SeedCount
+	// Products is the count of the products.
+	Products SeedCount
+	// Invoices is the count of the invoices.
+	Invoices SeedCount
+	// Sales is the count of the sales.
+	Sales SeedCount
+}
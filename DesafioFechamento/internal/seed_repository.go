@@ -0,0 +1,8 @@
+package internal
+
+// RepositorySeed is the interface that wraps the basic methods that a seed repository should implement.
+type RepositorySeed interface {
+	// Seed upserts the data into the database in a single transaction, keeping the ids.
+	// If dryRun is true the transaction is rolled back after the summary is computed.
+	Seed(d SeedData, dryRun bool) (s SeedSummary, err error)
+}
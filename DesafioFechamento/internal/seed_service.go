@@ -0,0 +1,7 @@
+package internal
+
+// ServiceSeed is the interface that wraps the basic methods that a seed service should implement.
+type ServiceSeed interface {
+	// Seed loads the data from the storages, validates it and saves it into the database.
+	Seed(dryRun bool) (s SeedSummary, err error)
+}
@@ -1,6 +1,9 @@
 package service
 
-import "app/internal"
+import (
+	"app/internal"
+	"math"
+)
 
 // NewCustomersDefault creates new default service for customer entity.
 func NewCustomersDefault(rp internal.RepositoryCustomer) *CustomersDefault {
@@ -19,13 +22,50 @@ func (s *CustomersDefault) FindAll() (c []internal.Customer, err error) {
 	return
 }
 
-func (s *CustomersDefault) GetTotalValues() (t []internal.CustomerTotalValue, err error) {
-	t, err = s.rp.GetTotalValues()
+// FindById returns a customer by its id.
+func (s *CustomersDefault) FindById(id int) (c internal.Customer, err error) {
+	c, err = s.rp.FindById(id)
 	return
 }
 
-func (s *CustomersDefault) GetSpentMoreMoney() (t []internal.CustomerSpentMoreMoney, err error) {
-	t, err = s.rp.GetSpentMoreMoney()
+// GetTotalValues returns the total spent by condition of the customers.
+func (s *CustomersDefault) GetTotalValues(f internal.CustomerReportFilter) (t []internal.CustomerTotalValue, err error) {
+	t, err = s.rp.GetTotalValues(f)
+	return
+}
+
+// GetSpentMoreMoney returns the customers that spent more money.
+func (s *CustomersDefault) GetSpentMoreMoney(f internal.CustomerReportFilter) (t []internal.CustomerSpentMoreMoney, err error) {
+	t, err = s.rp.GetSpentMoreMoney(f)
+	return
+}
+
+// GetPurchaseHistory returns the invoices of the customer with their sales and a lifetime summary.
+func (s *CustomersDefault) GetPurchaseHistory(id int) (h internal.CustomerPurchaseHistory, err error) {
+	// check the customer exists
+	h.Customer, err = s.rp.FindById(id)
+	if err != nil {
+		return
+	}
+
+	h.Purchases, err = s.rp.GetPurchases(id)
+	if err != nil {
+		return
+	}
+
+	// summary
+	h.Invoices = len(h.Purchases)
+	for _, p := range h.Purchases {
+		for _, l := range p.Lines {
+			h.Units += l.Quantity
+			h.Total += l.Total
+		}
+	}
+	h.Total = math.Round(h.Total*100) / 100
+	if h.Invoices > 0 {
+		h.FirstPurchase = h.Purchases[0].Datetime
+		h.LastPurchase = h.Purchases[h.Invoices-1].Datetime
+	}
 	return
 }
 
@@ -34,3 +74,43 @@ func (s *CustomersDefault) Save(c *internal.Customer) (err error) {
 	err = s.rp.Save(c)
 	return
 }
+
+// Update updates the customer.
+func (s *CustomersDefault) Update(c *internal.Customer) (err error) {
+	// check the customer exists
+	_, err = s.rp.FindById(c.Id)
+	if err != nil {
+		return
+	}
+
+	err = s.rp.Update(c)
+	return
+}
+
+// Patch updates the given fields of the customer.
+func (s *CustomersDefault) Patch(id int, p internal.CustomerPatch) (c internal.Customer, err error) {
+	c, err = s.rp.FindById(id)
+	if err != nil {
+		return
+	}
+
+	// apply the patch
+	if p.FirstName != nil {
+		c.FirstName = *p.FirstName
+	}
+	if p.LastName != nil {
+		c.LastName = *p.LastName
+	}
+	if p.Condition != nil {
+		c.Condition = *p.Condition
+	}
+
+	err = s.rp.Update(&c)
+	return
+}
+
+// Delete deletes the customer.
+func (s *CustomersDefault) Delete(id int) (err error) {
+	err = s.rp.Delete(id)
+	return
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+
+	"app/internal"
+)
+
+// NewExportDefault creates new default service for exporting the database.
+func NewExportDefault(rp internal.RepositoryExport) *ExportDefault {
+	return &ExportDefault{
+		rp: rp,
+	}
+}
+
+// ExportDefault is the default service implementation for exporting the database.
+type ExportDefault struct {
+	// rp is the repository for exporting the database.
+	rp internal.RepositoryExport
+}
+
+// Export saves every customer, product, invoice and sale of the database into the storages.
+// The entities are read from a single snapshot of the database, so the files can be seeded back.
+func (s *ExportDefault) Export(st internal.Storages) (sm internal.ExportSummary, err error) {
+	// read the database
+	d, err := s.rp.Snapshot()
+	if err != nil {
+		return sm, fmt.Errorf("reading the database: %w", err)
+	}
+
+	// customers
+	err = st.Customer.SaveAll(d.Customers)
+	if err != nil {
+		return sm, fmt.Errorf("exporting customers: %w", err)
+	}
+	sm.Customers = len(d.Customers)
+
+	// products
+	err = st.Product.SaveAll(d.Products)
+	if err != nil {
+		return sm, fmt.Errorf("exporting products: %w", err)
+	}
+	sm.Products = len(d.Products)
+
+	// invoices
+	err = st.Invoice.SaveAll(d.Invoices)
+	if err != nil {
+		return sm, fmt.Errorf("exporting invoices: %w", err)
+	}
+	sm.Invoices = len(d.Invoices)
+
+	// sales
+	err = st.Sale.SaveAll(d.Sales)
+	if err != nil {
+		return sm, fmt.Errorf("exporting sales: %w", err)
+	}
+	sm.Sales = len(d.Sales)
+
+	return
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"app/internal"
+	"app/internal/service"
+	"app/internal/storage"
+
+	"github.com/stretchr/testify/require"
+)
+
+// repositoryExportStub is a RepositoryExport returning fixed data.
+type repositoryExportStub struct {
+	d   internal.SeedData
+	err error
+}
+
+func (r *repositoryExportStub) Snapshot() (internal.SeedData, error) {
+	return r.d, r.err
+}
+
+// repositorySeedStub is a RepositorySeed keeping the data it is given.
+type repositorySeedStub struct {
+	d internal.SeedData
+}
+
+func (r *repositorySeedStub) Seed(d internal.SeedData, dryRun bool) (internal.SeedSummary, error) {
+	r.d = d
+	return internal.SeedSummary{}, nil
+}
+
+// exportData is a database with references between every entity, and values that need quoting in csv.
+var exportData = internal.SeedData{
+	Customers: []internal.Customer{
+		{Id: 1, CustomerAttributes: internal.CustomerAttributes{FirstName: "John", LastName: "O'Neil, Jr.", Condition: 1}},
+		{Id: 2, CustomerAttributes: internal.CustomerAttributes{FirstName: "Jane", LastName: "\"Doe\"", Condition: 0}},
+	},
+	Products: []internal.Product{
+		{Id: 7, ProductAttributes: internal.ProductAttributes{Description: "Apple, red", Price: 1.5}},
+		{Id: 8, ProductAttributes: internal.ProductAttributes{Description: "Pear", Price: 0.1}},
+	},
+	Invoices: []internal.Invoice{
+		{Id: 3, InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-01 10:00:00", Total: 3.1, CustomerId: 2}},
+	},
+	Sales: []internal.Sale{
+		{Id: 4, SaleAttributes: internal.SaleAttributes{Quantity: 2, ProductId: 7, InvoiceId: 3}},
+		{Id: 5, SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 8, InvoiceId: 3}},
+	},
+}
+
+func TestExportDefault_Export(t *testing.T) {
+	for _, format := range []internal.StorageFormat{internal.StorageFormatJSON, internal.StorageFormatCSV} {
+		t.Run("success - "+string(format)+" files seeded back", func(t *testing.T) {
+			st, err := storage.NewStorages(t.TempDir(), format)
+			require.NoError(t, err)
+			sv := service.NewExportDefault(&repositoryExportStub{d: exportData})
+
+			summary, err := sv.Export(st)
+
+			require.NoError(t, err)
+			require.Equal(t, internal.ExportSummary{Customers: 2, Products: 2, Invoices: 1, Sales: 2}, summary)
+			// - the seed reads and validates the files
+			rp := &repositorySeedStub{}
+			_, err = service.NewSeedDefault(rp, st.Customer, st.Product, st.Invoice, st.Sale).Seed(true)
+			require.NoError(t, err)
+			require.Equal(t, exportData, rp.d)
+		})
+	}
+
+	t.Run("error - snapshot fails", func(t *testing.T) {
+		st, err := storage.NewStorages(t.TempDir(), internal.StorageFormatJSON)
+		require.NoError(t, err)
+		errSnapshot := errors.New("connection lost")
+		sv := service.NewExportDefault(&repositoryExportStub{err: errSnapshot})
+
+		_, err = sv.Export(st)
+
+		require.ErrorIs(t, err, errSnapshot)
+	})
+}
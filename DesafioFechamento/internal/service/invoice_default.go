@@ -1,16 +1,26 @@
 package service
 
-import "app/internal"
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
 
 // NewInvoicesDefault creates new default service for invoice entity.
-func NewInvoicesDefault(rp internal.RepositoryInvoice) *InvoicesDefault {
-	return &InvoicesDefault{rp}
+func NewInvoicesDefault(rp internal.RepositoryInvoice, rpCustomer internal.RepositoryCustomer, rpProduct internal.RepositoryProduct) *InvoicesDefault {
+	return &InvoicesDefault{rp: rp, rpCustomer: rpCustomer, rpProduct: rpProduct}
 }
 
 // InvoicesDefault is the default service implementation for invoice entity.
 type InvoicesDefault struct {
 	// rp is the repository for invoice entity.
 	rp internal.RepositoryInvoice
+	// rpCustomer is the repository for customer entity, used to validate the customer of an invoice.
+	rpCustomer internal.RepositoryCustomer
+	// rpProduct is the repository for product entity, used to validate the products of a checkout.
+	rpProduct internal.RepositoryProduct
 }
 
 // FindAll returns all invoices.
@@ -19,8 +29,196 @@ func (s *InvoicesDefault) FindAll() (i []internal.Invoice, err error) {
 	return
 }
 
+// FindById returns an invoice by its id.
+func (s *InvoicesDefault) FindById(id int) (i internal.Invoice, err error) {
+	i, err = s.rp.FindById(id)
+	return
+}
+
+// Reconcile returns the invoices whose stored total drifts from the total of their sales.
+// If apply is true, the computed totals are written back to the invoices.
+func (s *InvoicesDefault) Reconcile(apply bool) (d []internal.InvoiceDiscrepancy, err error) {
+	all, err := s.rp.GetDiscrepancies()
+	if err != nil {
+		return
+	}
+
+	// keep only the invoices that drift
+	for _, ds := range all {
+		ds.Difference = difference(ds)
+		if ds.Difference != 0 {
+			d = append(d, ds)
+		}
+	}
+
+	if apply && len(d) > 0 {
+		err = s.rp.UpdateTotals(d)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// GetDiscrepancy returns the stored and computed totals of an invoice.
+func (s *InvoicesDefault) GetDiscrepancy(id int) (d internal.InvoiceDiscrepancy, err error) {
+	d, err = s.rp.GetDiscrepancyById(id)
+	if err != nil {
+		return
+	}
+	d.Difference = difference(d)
+	return
+}
+
+// difference returns the computed total minus the stored total, rounded to cents.
+func difference(d internal.InvoiceDiscrepancy) float64 {
+	return math.Round((d.ComputedTotal-d.StoredTotal)*100) / 100
+}
+
 // Save saves the invoice.
+// A new invoice has no sales yet, so its total starts at 0.
 func (s *InvoicesDefault) Save(i *internal.Invoice) (err error) {
+	(*i).Total = 0
+
+	err = s.validate(i)
+	if err != nil {
+		return
+	}
+
 	err = s.rp.Save(i)
 	return
-}
\ No newline at end of file
+}
+
+// Checkout saves the invoice and its sales atomically.
+func (s *InvoicesDefault) Checkout(i *internal.Invoice, sl []internal.Sale) (err error) {
+	err = s.validateCheckout(i, sl)
+	if err != nil {
+		return
+	}
+
+	err = s.rp.Checkout(i, sl)
+	return
+}
+
+// Update updates the invoice.
+func (s *InvoicesDefault) Update(i *internal.Invoice) (err error) {
+	// check the invoice exists
+	_, err = s.rp.FindById(i.Id)
+	if err != nil {
+		return
+	}
+
+	err = s.validate(i)
+	if err != nil {
+		return
+	}
+
+	err = s.rp.Update(i)
+	return
+}
+
+// Patch updates the given fields of the invoice.
+func (s *InvoicesDefault) Patch(id int, p internal.InvoicePatch) (i internal.Invoice, err error) {
+	i, err = s.rp.FindById(id)
+	if err != nil {
+		return
+	}
+
+	// apply the patch
+	if p.Datetime != nil {
+		i.Datetime = *p.Datetime
+	}
+	if p.CustomerId != nil {
+		i.CustomerId = *p.CustomerId
+	}
+
+	err = s.validate(&i)
+	if err != nil {
+		return
+	}
+
+	err = s.rp.Update(&i)
+	return
+}
+
+// Delete deletes the invoice.
+func (s *InvoicesDefault) Delete(id int) (err error) {
+	err = s.rp.Delete(id)
+	return
+}
+
+// validate checks the datetime of the invoice and that its customer exists.
+func (s *InvoicesDefault) validate(i *internal.Invoice) (err error) {
+	// values
+	if f, ok := validateDatetime(i.Datetime); !ok {
+		err = &internal.FieldsError{Err: internal.ErrServiceInvalidField, Fields: []internal.FieldError{f}}
+		return
+	}
+
+	// references
+	_, err = s.rpCustomer.FindById(i.CustomerId)
+	if errors.Is(err, internal.ErrRepositoryCustomerNotFound) {
+		err = &internal.FieldsError{
+			Err:    internal.ErrServiceReferenceNotFound,
+			Fields: []internal.FieldError{{Field: "customer_id", Message: "customer not found"}},
+		}
+	}
+	return
+}
+
+// validateDatetime checks the datetime of an invoice is formatted as time.DateTime,
+// the layout the invoices are written with, and returns the field error otherwise.
+func validateDatetime(datetime string) (f internal.FieldError, ok bool) {
+	_, err := time.Parse(time.DateTime, datetime)
+	if err != nil {
+		return internal.FieldError{Field: "datetime", Message: "must be formatted as 2006-01-02 15:04:05"}, false
+	}
+	return f, true
+}
+
+// validateCheckout checks the datetime and the quantities of the checkout and that its customer and products exist.
+func (s *InvoicesDefault) validateCheckout(i *internal.Invoice, sl []internal.Sale) (err error) {
+	// values
+	var fields []internal.FieldError
+	if f, ok := validateDatetime(i.Datetime); !ok {
+		fields = append(fields, f)
+	}
+	if len(sl) == 0 {
+		fields = append(fields, internal.FieldError{Field: "products", Message: "must have at least one product"})
+	}
+	for ix, v := range sl {
+		if v.Quantity <= 0 {
+			fields = append(fields, internal.FieldError{Field: fmt.Sprintf("products[%d].quantity", ix), Message: "must be greater than 0"})
+		}
+	}
+	if len(fields) > 0 {
+		err = &internal.FieldsError{Err: internal.ErrServiceInvalidField, Fields: fields}
+		return
+	}
+
+	// references
+	// - customer
+	_, err = s.rpCustomer.FindById(i.CustomerId)
+	switch {
+	case errors.Is(err, internal.ErrRepositoryCustomerNotFound):
+		fields = append(fields, internal.FieldError{Field: "customer_id", Message: "customer not found"})
+	case err != nil:
+		return
+	}
+	// - products
+	for ix, v := range sl {
+		_, err = s.rpProduct.FindById(v.ProductId)
+		switch {
+		case errors.Is(err, internal.ErrRepositoryProductNotFound):
+			fields = append(fields, internal.FieldError{Field: fmt.Sprintf("products[%d].product_id", ix), Message: "product not found"})
+		case err != nil:
+			return
+		}
+	}
+
+	err = nil
+	if len(fields) > 0 {
+		err = &internal.FieldsError{Err: internal.ErrServiceReferenceNotFound, Fields: fields}
+	}
+	return
+}
@@ -0,0 +1,251 @@
+package service_test
+
+import (
+	"testing"
+
+	"app/internal"
+	"app/internal/service"
+
+	"github.com/stretchr/testify/require"
+)
+
+// repositoryCustomerStub is a RepositoryCustomer finding the customers of c.
+type repositoryCustomerStub struct {
+	internal.RepositoryCustomer
+	c map[int]internal.Customer
+}
+
+func (r *repositoryCustomerStub) FindById(id int) (internal.Customer, error) {
+	c, ok := r.c[id]
+	if !ok {
+		return internal.Customer{}, internal.ErrRepositoryCustomerNotFound
+	}
+	return c, nil
+}
+
+// repositoryProductStub is a RepositoryProduct finding the products of p and keeping the ones written.
+type repositoryProductStub struct {
+	internal.RepositoryProduct
+	p map[int]internal.Product
+}
+
+func (r *repositoryProductStub) FindById(id int) (internal.Product, error) {
+	p, ok := r.p[id]
+	if !ok {
+		return internal.Product{}, internal.ErrRepositoryProductNotFound
+	}
+	return p, nil
+}
+
+func (r *repositoryProductStub) Save(p *internal.Product) error {
+	p.Id = len(r.p) + 1
+	r.p[p.Id] = *p
+	return nil
+}
+
+func (r *repositoryProductStub) Update(p *internal.Product) error {
+	if _, ok := r.p[p.Id]; !ok {
+		return internal.ErrRepositoryProductNotFound
+	}
+	r.p[p.Id] = *p
+	return nil
+}
+
+// repositoryInvoiceStub is a RepositoryInvoice finding the invoices of i and keeping the ones written.
+type repositoryInvoiceStub struct {
+	internal.RepositoryInvoice
+	i map[int]internal.Invoice
+	// sales are the sales of the last checkout.
+	sales []internal.Sale
+}
+
+func (r *repositoryInvoiceStub) FindById(id int) (internal.Invoice, error) {
+	i, ok := r.i[id]
+	if !ok {
+		return internal.Invoice{}, internal.ErrRepositoryInvoiceNotFound
+	}
+	return i, nil
+}
+
+func (r *repositoryInvoiceStub) Save(i *internal.Invoice) error {
+	i.Id = len(r.i) + 1
+	r.i[i.Id] = *i
+	return nil
+}
+
+func (r *repositoryInvoiceStub) Update(i *internal.Invoice) error {
+	r.i[i.Id] = *i
+	return nil
+}
+
+func (r *repositoryInvoiceStub) Checkout(i *internal.Invoice, s []internal.Sale) error {
+	i.Id = len(r.i) + 1
+	r.i[i.Id] = *i
+	r.sales = s
+	return nil
+}
+
+// newInvoicesDefault returns the service on stubs with customer 1, product 1 and invoice 1.
+func newInvoicesDefault() (sv *service.InvoicesDefault, rp *repositoryInvoiceStub) {
+	rp = &repositoryInvoiceStub{i: map[int]internal.Invoice{
+		1: {Id: 1, InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-01 10:00:00", Total: 1.5, CustomerId: 1}},
+	}}
+	rpCustomer := &repositoryCustomerStub{c: map[int]internal.Customer{1: {Id: 1}}}
+	rpProduct := &repositoryProductStub{p: map[int]internal.Product{1: {Id: 1, ProductAttributes: internal.ProductAttributes{Price: 1.5}}}}
+	sv = service.NewInvoicesDefault(rp, rpCustomer, rpProduct)
+	return
+}
+
+// requireFieldsError requires err to be a FieldsError of kind target with fields.
+func requireFieldsError(t *testing.T, err error, target error, fields ...internal.FieldError) {
+	t.Helper()
+	require.ErrorIs(t, err, target)
+	var fe *internal.FieldsError
+	require.ErrorAs(t, err, &fe)
+	require.Equal(t, fields, fe.Fields)
+}
+
+func TestInvoicesDefault_Save(t *testing.T) {
+	t.Run("success - invoice saved", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02 10:00:00", CustomerId: 1}}
+
+		err := sv.Save(&i)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, i.Id)
+		require.Len(t, rp.i, 2)
+	})
+
+	t.Run("success - total starts at 0", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02 10:00:00", Total: 150.75, CustomerId: 1}}
+
+		err := sv.Save(&i)
+
+		require.NoError(t, err)
+		require.Equal(t, 0.0, i.Total)
+		require.Equal(t, 0.0, rp.i[i.Id].Total)
+	})
+
+	t.Run("error - customer not found", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02 10:00:00", CustomerId: 2}}
+
+		err := sv.Save(&i)
+
+		requireFieldsError(t, err, internal.ErrServiceReferenceNotFound, internal.FieldError{Field: "customer_id", Message: "customer not found"})
+		require.Len(t, rp.i, 1)
+	})
+
+	t.Run("error - invalid datetime", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "02/01/2022", CustomerId: 1}}
+
+		err := sv.Save(&i)
+
+		requireFieldsError(t, err, internal.ErrServiceInvalidField, internal.FieldError{Field: "datetime", Message: "must be formatted as 2006-01-02 15:04:05"})
+		require.Len(t, rp.i, 1)
+	})
+}
+
+func TestInvoicesDefault_Patch(t *testing.T) {
+	t.Run("error - customer not found", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		customerId := 2
+
+		_, err := sv.Patch(1, internal.InvoicePatch{CustomerId: &customerId})
+
+		requireFieldsError(t, err, internal.ErrServiceReferenceNotFound, internal.FieldError{Field: "customer_id", Message: "customer not found"})
+		require.Equal(t, 1, rp.i[1].CustomerId)
+	})
+
+	t.Run("error - invalid datetime", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		datetime := "2022-01-32 10:00:00"
+
+		_, err := sv.Patch(1, internal.InvoicePatch{Datetime: &datetime})
+
+		requireFieldsError(t, err, internal.ErrServiceInvalidField, internal.FieldError{Field: "datetime", Message: "must be formatted as 2006-01-02 15:04:05"})
+		require.Equal(t, "2022-01-01 10:00:00", rp.i[1].Datetime)
+	})
+
+	t.Run("error - invoice not found", func(t *testing.T) {
+		sv, _ := newInvoicesDefault()
+
+		_, err := sv.Patch(2, internal.InvoicePatch{})
+
+		require.ErrorIs(t, err, internal.ErrRepositoryInvoiceNotFound)
+	})
+}
+
+func TestInvoicesDefault_Checkout(t *testing.T) {
+	t.Run("success - invoice and sales saved", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02 10:00:00", CustomerId: 1}}
+		sl := []internal.Sale{{SaleAttributes: internal.SaleAttributes{Quantity: 2, ProductId: 1}}}
+
+		err := sv.Checkout(&i, sl)
+
+		require.NoError(t, err)
+		require.Equal(t, sl, rp.sales)
+	})
+
+	t.Run("error - no products", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02 10:00:00", CustomerId: 1}}
+
+		err := sv.Checkout(&i, nil)
+
+		requireFieldsError(t, err, internal.ErrServiceInvalidField, internal.FieldError{Field: "products", Message: "must have at least one product"})
+		require.Len(t, rp.i, 1)
+	})
+
+	t.Run("error - invalid datetime and no products", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02", CustomerId: 1}}
+
+		err := sv.Checkout(&i, nil)
+
+		requireFieldsError(t, err, internal.ErrServiceInvalidField,
+			internal.FieldError{Field: "datetime", Message: "must be formatted as 2006-01-02 15:04:05"},
+			internal.FieldError{Field: "products", Message: "must have at least one product"},
+		)
+		require.Len(t, rp.i, 1)
+	})
+
+	t.Run("error - non positive quantities", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02 10:00:00", CustomerId: 1}}
+		sl := []internal.Sale{
+			{SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 1}},
+			{SaleAttributes: internal.SaleAttributes{Quantity: 0, ProductId: 1}},
+			{SaleAttributes: internal.SaleAttributes{Quantity: -1, ProductId: 2}},
+		}
+
+		err := sv.Checkout(&i, sl)
+
+		requireFieldsError(t, err, internal.ErrServiceInvalidField,
+			internal.FieldError{Field: "products[1].quantity", Message: "must be greater than 0"},
+			internal.FieldError{Field: "products[2].quantity", Message: "must be greater than 0"},
+		)
+		require.Len(t, rp.i, 1)
+	})
+
+	t.Run("error - customer and product not found", func(t *testing.T) {
+		sv, rp := newInvoicesDefault()
+		i := internal.Invoice{InvoiceAttributes: internal.InvoiceAttributes{Datetime: "2022-01-02 10:00:00", CustomerId: 2}}
+		sl := []internal.Sale{
+			{SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 1}},
+			{SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 2}},
+		}
+
+		err := sv.Checkout(&i, sl)
+
+		requireFieldsError(t, err, internal.ErrServiceReferenceNotFound,
+			internal.FieldError{Field: "customer_id", Message: "customer not found"},
+			internal.FieldError{Field: "products[1].product_id", Message: "product not found"},
+		)
+		require.Len(t, rp.i, 1)
+	})
+}
@@ -19,13 +19,83 @@ func (s *ProductsDefault) FindAll() (p []internal.Product, err error) {
 	return
 }
 
-func (s *ProductsDefault) GetBestSelling() (p []internal.ProductBestSelling, err error) {
-	p, err = s.rp.GetBestSelling()
+// FindById returns a product by its id.
+func (s *ProductsDefault) FindById(id int) (p internal.Product, err error) {
+	p, err = s.rp.FindById(id)
+	return
+}
+
+// GetBestSelling returns the best-selling products.
+func (s *ProductsDefault) GetBestSelling(f internal.ProductBestSellingFilter) (p []internal.ProductBestSelling, err error) {
+	p, err = s.rp.GetBestSelling(f)
 	return
 }
 
 // Save saves the product.
 func (s *ProductsDefault) Save(p *internal.Product) (err error) {
+	err = validateProduct(p)
+	if err != nil {
+		return
+	}
+
 	err = s.rp.Save(p)
 	return
 }
+
+// Update updates the product.
+func (s *ProductsDefault) Update(p *internal.Product) (err error) {
+	// check the product exists
+	_, err = s.rp.FindById(p.Id)
+	if err != nil {
+		return
+	}
+
+	err = validateProduct(p)
+	if err != nil {
+		return
+	}
+
+	err = s.rp.Update(p)
+	return
+}
+
+// Patch updates the given fields of the product.
+func (s *ProductsDefault) Patch(id int, pp internal.ProductPatch) (p internal.Product, err error) {
+	p, err = s.rp.FindById(id)
+	if err != nil {
+		return
+	}
+
+	// apply the patch
+	if pp.Description != nil {
+		p.Description = *pp.Description
+	}
+	if pp.Price != nil {
+		p.Price = *pp.Price
+	}
+
+	err = validateProduct(&p)
+	if err != nil {
+		return
+	}
+
+	err = s.rp.Update(&p)
+	return
+}
+
+// Delete deletes the product.
+func (s *ProductsDefault) Delete(id int) (err error) {
+	err = s.rp.Delete(id)
+	return
+}
+
+// validateProduct checks the price of the product.
+func validateProduct(p *internal.Product) (err error) {
+	if p.Price < 0 {
+		err = &internal.FieldsError{
+			Err:    internal.ErrServiceInvalidField,
+			Fields: []internal.FieldError{{Field: "price", Message: "must not be negative"}},
+		}
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package service_test
+
+import (
+	"testing"
+
+	"app/internal"
+	"app/internal/service"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductsDefault_Save(t *testing.T) {
+	t.Run("success - free product saved", func(t *testing.T) {
+		rp := &repositoryProductStub{p: map[int]internal.Product{}}
+		sv := service.NewProductsDefault(rp)
+		p := internal.Product{ProductAttributes: internal.ProductAttributes{Description: "Sample", Price: 0}}
+
+		err := sv.Save(&p)
+
+		require.NoError(t, err)
+		require.Len(t, rp.p, 1)
+	})
+
+	t.Run("error - negative price", func(t *testing.T) {
+		rp := &repositoryProductStub{p: map[int]internal.Product{}}
+		sv := service.NewProductsDefault(rp)
+		p := internal.Product{ProductAttributes: internal.ProductAttributes{Description: "Apple", Price: -0.01}}
+
+		err := sv.Save(&p)
+
+		requireFieldsError(t, err, internal.ErrServiceInvalidField, internal.FieldError{Field: "price", Message: "must not be negative"})
+		require.Empty(t, rp.p)
+	})
+}
+
+func TestProductsDefault_Patch(t *testing.T) {
+	t.Run("error - negative price", func(t *testing.T) {
+		rp := &repositoryProductStub{p: map[int]internal.Product{1: {Id: 1, ProductAttributes: internal.ProductAttributes{Description: "Apple", Price: 1.5}}}}
+		sv := service.NewProductsDefault(rp)
+		price := -1.0
+
+		_, err := sv.Patch(1, internal.ProductPatch{Price: &price})
+
+		requireFieldsError(t, err, internal.ErrServiceInvalidField, internal.FieldError{Field: "price", Message: "must not be negative"})
+		require.Equal(t, 1.5, rp.p[1].Price)
+	})
+}
@@ -0,0 +1,20 @@
+package service
+
+import "app/internal"
+
+// NewReportsDefault creates new default service for reports.
+func NewReportsDefault(rp internal.RepositoryReport) *ReportsDefault {
+	return &ReportsDefault{rp}
+}
+
+// ReportsDefault is the default service implementation for reports.
+type ReportsDefault struct {
+	// rp is the repository for reports.
+	rp internal.RepositoryReport
+}
+
+// GetSales returns the sales bucketed by period.
+func (s *ReportsDefault) GetSales(f internal.SalesReportFilter) (b []internal.SalesReportBucket, err error) {
+	b, err = s.rp.GetSales(f)
+	return
+}
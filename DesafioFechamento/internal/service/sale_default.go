@@ -1,16 +1,23 @@
 package service
 
-import "app/internal"
+import (
+	"app/internal"
+	"errors"
+)
 
 // NewSalesDefault creates new default service for sale entity.
-func NewSalesDefault(rp internal.RepositorySale) *SalesDefault {
-	return &SalesDefault{rp}
+func NewSalesDefault(rp internal.RepositorySale, rpProduct internal.RepositoryProduct, rpInvoice internal.RepositoryInvoice) *SalesDefault {
+	return &SalesDefault{rp: rp, rpProduct: rpProduct, rpInvoice: rpInvoice}
 }
 
 // SalesDefault is the default service implementation for sale entity.
 type SalesDefault struct {
 	// rp is the repository for sale entity.
 	rp internal.RepositorySale
+	// rpProduct is the repository for product entity, used to validate the product of a sale.
+	rpProduct internal.RepositoryProduct
+	// rpInvoice is the repository for invoice entity, used to validate the invoice of a sale.
+	rpInvoice internal.RepositoryInvoice
 }
 
 // FindAll returns all sales.
@@ -19,8 +26,100 @@ func (sv *SalesDefault) FindAll() (s []internal.Sale, err error) {
 	return
 }
 
+// FindById returns a sale by its id.
+func (sv *SalesDefault) FindById(id int) (s internal.Sale, err error) {
+	s, err = sv.rp.FindById(id)
+	return
+}
+
 // Save saves the sale.
 func (sv *SalesDefault) Save(s *internal.Sale) (err error) {
+	err = sv.validate(s)
+	if err != nil {
+		return
+	}
+
 	err = sv.rp.Save(s)
 	return
-}
\ No newline at end of file
+}
+
+// Update updates the sale.
+func (sv *SalesDefault) Update(s *internal.Sale) (err error) {
+	err = sv.validate(s)
+	if err != nil {
+		return
+	}
+
+	err = sv.rp.Update(s)
+	return
+}
+
+// Patch updates the given fields of the sale.
+func (sv *SalesDefault) Patch(id int, p internal.SalePatch) (s internal.Sale, err error) {
+	s, err = sv.rp.FindById(id)
+	if err != nil {
+		return
+	}
+
+	// apply the patch
+	if p.Quantity != nil {
+		s.Quantity = *p.Quantity
+	}
+	if p.ProductId != nil {
+		s.ProductId = *p.ProductId
+	}
+	if p.InvoiceId != nil {
+		s.InvoiceId = *p.InvoiceId
+	}
+
+	err = sv.validate(&s)
+	if err != nil {
+		return
+	}
+
+	err = sv.rp.Update(&s)
+	return
+}
+
+// Delete deletes the sale.
+func (sv *SalesDefault) Delete(id int) (err error) {
+	err = sv.rp.Delete(id)
+	return
+}
+
+// validate checks the quantity of the sale and that its product and invoice exist.
+func (sv *SalesDefault) validate(s *internal.Sale) (err error) {
+	// values
+	if s.Quantity <= 0 {
+		err = &internal.FieldsError{
+			Err:    internal.ErrServiceInvalidField,
+			Fields: []internal.FieldError{{Field: "quantity", Message: "must be greater than 0"}},
+		}
+		return
+	}
+
+	// references
+	var fields []internal.FieldError
+	// - product
+	_, err = sv.rpProduct.FindById(s.ProductId)
+	switch {
+	case errors.Is(err, internal.ErrRepositoryProductNotFound):
+		fields = append(fields, internal.FieldError{Field: "product_id", Message: "product not found"})
+	case err != nil:
+		return
+	}
+	// - invoice
+	_, err = sv.rpInvoice.FindById(s.InvoiceId)
+	switch {
+	case errors.Is(err, internal.ErrRepositoryInvoiceNotFound):
+		fields = append(fields, internal.FieldError{Field: "invoice_id", Message: "invoice not found"})
+	case err != nil:
+		return
+	}
+
+	err = nil
+	if len(fields) > 0 {
+		err = &internal.FieldsError{Err: internal.ErrServiceReferenceNotFound, Fields: fields}
+	}
+	return
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"testing"
+
+	"app/internal"
+	"app/internal/service"
+
+	"github.com/stretchr/testify/require"
+)
+
+// repositorySaleStub is a RepositorySale finding the sales of s and keeping the ones written.
+type repositorySaleStub struct {
+	internal.RepositorySale
+	s map[int]internal.Sale
+}
+
+func (r *repositorySaleStub) FindById(id int) (internal.Sale, error) {
+	s, ok := r.s[id]
+	if !ok {
+		return internal.Sale{}, internal.ErrRepositorySaleNotFound
+	}
+	return s, nil
+}
+
+func (r *repositorySaleStub) Save(s *internal.Sale) error {
+	s.Id = len(r.s) + 1
+	r.s[s.Id] = *s
+	return nil
+}
+
+func (r *repositorySaleStub) Update(s *internal.Sale) error {
+	if _, ok := r.s[s.Id]; !ok {
+		return internal.ErrRepositorySaleNotFound
+	}
+	r.s[s.Id] = *s
+	return nil
+}
+
+// newSalesDefault returns the service on stubs with product 1, invoice 1 and sale 1.
+func newSalesDefault() (sv *service.SalesDefault, rp *repositorySaleStub) {
+	rp = &repositorySaleStub{s: map[int]internal.Sale{
+		1: {Id: 1, SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 1, InvoiceId: 1}},
+	}}
+	rpProduct := &repositoryProductStub{p: map[int]internal.Product{1: {Id: 1}}}
+	rpInvoice := &repositoryInvoiceStub{i: map[int]internal.Invoice{1: {Id: 1}}}
+	sv = service.NewSalesDefault(rp, rpProduct, rpInvoice)
+	return
+}
+
+func TestSalesDefault_Save(t *testing.T) {
+	t.Run("success - sale saved", func(t *testing.T) {
+		sv, rp := newSalesDefault()
+		s := internal.Sale{SaleAttributes: internal.SaleAttributes{Quantity: 2, ProductId: 1, InvoiceId: 1}}
+
+		err := sv.Save(&s)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, s.Id)
+		require.Len(t, rp.s, 2)
+	})
+
+	t.Run("error - non positive quantity", func(t *testing.T) {
+		for _, q := range []int{0, -1} {
+			sv, rp := newSalesDefault()
+			s := internal.Sale{SaleAttributes: internal.SaleAttributes{Quantity: q, ProductId: 1, InvoiceId: 1}}
+
+			err := sv.Save(&s)
+
+			requireFieldsError(t, err, internal.ErrServiceInvalidField, internal.FieldError{Field: "quantity", Message: "must be greater than 0"})
+			require.Len(t, rp.s, 1)
+		}
+	})
+
+	t.Run("error - product and invoice not found", func(t *testing.T) {
+		sv, rp := newSalesDefault()
+		s := internal.Sale{SaleAttributes: internal.SaleAttributes{Quantity: 1, ProductId: 2, InvoiceId: 2}}
+
+		err := sv.Save(&s)
+
+		requireFieldsError(t, err, internal.ErrServiceReferenceNotFound,
+			internal.FieldError{Field: "product_id", Message: "product not found"},
+			internal.FieldError{Field: "invoice_id", Message: "invoice not found"},
+		)
+		require.Len(t, rp.s, 1)
+	})
+}
+
+func TestSalesDefault_Update(t *testing.T) {
+	t.Run("success - sale updated", func(t *testing.T) {
+		sv, rp := newSalesDefault()
+		s := internal.Sale{Id: 1, SaleAttributes: internal.SaleAttributes{Quantity: 3, ProductId: 1, InvoiceId: 1}}
+
+		err := sv.Update(&s)
+
+		require.NoError(t, err)
+		require.Equal(t, s, rp.s[1])
+	})
+
+	t.Run("error - invoice not found", func(t *testing.T) {
+		sv, rp := newSalesDefault()
+		s := internal.Sale{Id: 1, SaleAttributes: internal.SaleAttributes{Quantity: 3, ProductId: 1, InvoiceId: 2}}
+
+		err := sv.Update(&s)
+
+		requireFieldsError(t, err, internal.ErrServiceReferenceNotFound, internal.FieldError{Field: "invoice_id", Message: "invoice not found"})
+		require.Equal(t, 1, rp.s[1].Quantity)
+	})
+
+	t.Run("error - sale not found", func(t *testing.T) {
+		sv, _ := newSalesDefault()
+		s := internal.Sale{Id: 2, SaleAttributes: internal.SaleAttributes{Quantity: 3, ProductId: 1, InvoiceId: 1}}
+
+		err := sv.Update(&s)
+
+		require.ErrorIs(t, err, internal.ErrRepositorySaleNotFound)
+	})
+}
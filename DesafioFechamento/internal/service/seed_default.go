@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+
+	"app/internal"
+)
+
+// NewSeedDefault creates new default service for seeding the database.
+func NewSeedDefault(rp internal.RepositorySeed, stCustomer internal.StorageCustomer, stProduct internal.StorageProduct, stInvoice internal.StorageInvoice, stSale internal.StorageSale) *SeedDefault {
+	return &SeedDefault{
+		rp:         rp,
+		stCustomer: stCustomer,
+		stProduct:  stProduct,
+		stInvoice:  stInvoice,
+		stSale:     stSale,
+	}
+}
+
+// SeedDefault is the default service implementation for seeding the database.
+type SeedDefault struct {
+	// rp is the repository for seeding the database.
+	rp internal.RepositorySeed
+	// stCustomer is the storage for customers.
+	stCustomer internal.StorageCustomer
+	// stProduct is the storage for products.
+	stProduct internal.StorageProduct
+	// stInvoice is the storage for invoices.
+	stInvoice internal.StorageInvoice
+	// stSale is the storage for sales.
+	stSale internal.StorageSale
+}
+
+// Seed loads the data from the storages, validates it and saves it into the database.
+func (s *SeedDefault) Seed(dryRun bool) (sm internal.SeedSummary, err error) {
+	// load the data
+	var d internal.SeedData
+	d.Customers, err = s.stCustomer.FindAll()
+	if err != nil {
+		return sm, fmt.Errorf("loading customers: %w", err)
+	}
+	d.Products, err = s.stProduct.FindAll()
+	if err != nil {
+		return sm, fmt.Errorf("loading products: %w", err)
+	}
+	d.Invoices, err = s.stInvoice.FindAll()
+	if err != nil {
+		return sm, fmt.Errorf("loading invoices: %w", err)
+	}
+	d.Sales, err = s.stSale.FindAll()
+	if err != nil {
+		return sm, fmt.Errorf("loading sales: %w", err)
+	}
+
+	// validate the data
+	err = validateSeed(d)
+	if err != nil {
+		return
+	}
+
+	sm, err = s.rp.Seed(d, dryRun)
+	return
+}
+
+// validateSeed checks the ids of the data are valid and unique, and that every reference
+// points at an entity of the same data.
+func validateSeed(d internal.SeedData) (err error) {
+	var fields []internal.FieldError
+	// - ids
+	checkIds := func(entity string, ids []int) map[int]bool {
+		seen := make(map[int]bool, len(ids))
+		for ix, id := range ids {
+			field := fmt.Sprintf("%s[%d].id", entity, ix)
+			switch {
+			case id <= 0:
+				fields = append(fields, internal.FieldError{Field: field, Message: "must be greater than 0"})
+			case seen[id]:
+				fields = append(fields, internal.FieldError{Field: field, Message: fmt.Sprintf("duplicated id %d", id)})
+			}
+			seen[id] = true
+		}
+		return seen
+	}
+	ids := make([]int, len(d.Customers))
+	for ix, c := range d.Customers {
+		ids[ix] = c.Id
+	}
+	customers := checkIds("customers", ids)
+	ids = make([]int, len(d.Products))
+	for ix, p := range d.Products {
+		ids[ix] = p.Id
+	}
+	products := checkIds("products", ids)
+	ids = make([]int, len(d.Invoices))
+	for ix, i := range d.Invoices {
+		ids[ix] = i.Id
+	}
+	invoices := checkIds("invoices", ids)
+	ids = make([]int, len(d.Sales))
+	for ix, sl := range d.Sales {
+		ids[ix] = sl.Id
+	}
+	checkIds("sales", ids)
+	if len(fields) > 0 {
+		err = &internal.FieldsError{Err: internal.ErrServiceInvalidField, Fields: fields}
+		return
+	}
+
+	// - references
+	for ix, i := range d.Invoices {
+		if !customers[i.CustomerId] {
+			fields = append(fields, internal.FieldError{Field: fmt.Sprintf("invoices[%d].customer_id", ix), Message: fmt.Sprintf("customer %d not found", i.CustomerId)})
+		}
+	}
+	for ix, sl := range d.Sales {
+		if !invoices[sl.InvoiceId] {
+			fields = append(fields, internal.FieldError{Field: fmt.Sprintf("sales[%d].invoice_id", ix), Message: fmt.Sprintf("invoice %d not found", sl.InvoiceId)})
+		}
+		if !products[sl.ProductId] {
+			fields = append(fields, internal.FieldError{Field: fmt.Sprintf("sales[%d].product_id", ix), Message: fmt.Sprintf("product %d not found", sl.ProductId)})
+		}
+	}
+	if len(fields) > 0 {
+		err = &internal.FieldsError{Err: internal.ErrServiceReferenceNotFound, Fields: fields}
+	}
+	return
+}
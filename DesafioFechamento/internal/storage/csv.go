@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+var (
+	// ErrStorageCSVHeaderInvalid is returned when the header of a csv file does not match the expected columns.
+	ErrStorageCSVHeaderInvalid = errors.New("storage: invalid csv header")
+)
+
+// readCSV returns the records of the csv file at path, without the header.
+func readCSV(path string, header []string) (records [][]string, err error) {
+	// open file
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// decode file
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = len(header)
+	records, err = r.ReadAll()
+	if err != nil {
+		return
+	}
+	if len(records) == 0 || !slices.Equal(records[0], header) {
+		err = fmt.Errorf("%w: %s", ErrStorageCSVHeaderInvalid, path)
+		return
+	}
+	records = records[1:]
+	return
+}
+
+// writeCSV replaces the csv file at path with the header followed by the records.
+func writeCSV(path string, header []string, records [][]string) (err error) {
+	// create file
+	file, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// encode file
+	w := csv.NewWriter(file)
+	err = w.Write(header)
+	if err != nil {
+		return
+	}
+	err = w.WriteAll(records)
+	return
+}
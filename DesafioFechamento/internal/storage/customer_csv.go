@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"app/internal"
+	"fmt"
+	"strconv"
+)
+
+// NewCustomersStorageCSV creates a new CustomersStorageCSV reading and writing the csv file at path.
+func NewCustomersStorageCSV(path string) *CustomersStorageCSV {
+	return &CustomersStorageCSV{pathCsv: path}
+}
+
+// CustomersStorageCSV is the csv implementation of internal.StorageCustomer.
+// The first line of the file is the header with the same names of the json fields.
+type CustomersStorageCSV struct {
+	pathCsv string
+}
+
+// customersCSVHeader is the header of the customers csv file.
+var customersCSVHeader = []string{"id", "first_name", "last_name", "condition"}
+
+// FindAll returns all customers of the csv file.
+func (s *CustomersStorageCSV) FindAll() (c []internal.Customer, err error) {
+	records, err := readCSV(s.pathCsv, customersCSVHeader)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	for ix, r := range records {
+		id, err := strconv.Atoi(r[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: id: %w", ix+2, err)
+		}
+		condition, err := strconv.Atoi(r[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: condition: %w", ix+2, err)
+		}
+		c = append(c, internal.Customer{
+			Id: id,
+			CustomerAttributes: internal.CustomerAttributes{
+				FirstName: r[1],
+				LastName:  r[2],
+				Condition: condition,
+			},
+		})
+	}
+
+	return
+}
+
+// SaveAll replaces the customers of the csv file.
+func (s *CustomersStorageCSV) SaveAll(c []internal.Customer) (err error) {
+	records := make([][]string, len(c))
+	for ix, cust := range c {
+		records[ix] = []string{strconv.Itoa(cust.Id), cust.FirstName, cust.LastName, strconv.Itoa(cust.Condition)}
+	}
+
+	err = writeCSV(s.pathCsv, customersCSVHeader, records)
+	return
+}
@@ -50,3 +50,27 @@ func (s *CustomersStorage) FindAll() (c []internal.Customer, err error) {
 
 	return
 }
+
+func (s *CustomersStorage) SaveAll(c []internal.Customer) (err error) {
+	// deserialize
+	customersJSON := make([]CustomersJSON, len(c))
+	for ix, cust := range c {
+		customersJSON[ix] = CustomersJSON{
+			Id:        cust.Id,
+			FirstName: cust.FirstName,
+			LastName:  cust.LastName,
+			Condition: cust.Condition,
+		}
+	}
+
+	// create file
+	file, err := os.Create(s.pathJson)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// encode file
+	err = json.NewEncoder(file).Encode(customersJSON)
+	return
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"app/internal"
+	"fmt"
+	"strconv"
+)
+
+// NewInvoicesStorageCSV creates a new InvoicesStorageCSV reading and writing the csv file at path.
+func NewInvoicesStorageCSV(path string) *InvoicesStorageCSV {
+	return &InvoicesStorageCSV{pathCsv: path}
+}
+
+// InvoicesStorageCSV is the csv implementation of internal.StorageInvoice.
+// The first line of the file is the header with the same names of the json fields.
+type InvoicesStorageCSV struct {
+	pathCsv string
+}
+
+// invoicesCSVHeader is the header of the invoices csv file.
+var invoicesCSVHeader = []string{"id", "datetime", "customer_id", "total"}
+
+// FindAll returns all invoices of the csv file.
+func (s *InvoicesStorageCSV) FindAll() (i []internal.Invoice, err error) {
+	records, err := readCSV(s.pathCsv, invoicesCSVHeader)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	for ix, r := range records {
+		id, err := strconv.Atoi(r[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: id: %w", ix+2, err)
+		}
+		customerId, err := strconv.Atoi(r[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: customer_id: %w", ix+2, err)
+		}
+		total, err := strconv.ParseFloat(r[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: total: %w", ix+2, err)
+		}
+		i = append(i, internal.Invoice{
+			Id: id,
+			InvoiceAttributes: internal.InvoiceAttributes{
+				Datetime:   r[1],
+				CustomerId: customerId,
+				Total:      total,
+			},
+		})
+	}
+
+	return
+}
+
+// SaveAll replaces the invoices of the csv file.
+func (s *InvoicesStorageCSV) SaveAll(i []internal.Invoice) (err error) {
+	records := make([][]string, len(i))
+	for ix, inv := range i {
+		records[ix] = []string{strconv.Itoa(inv.Id), inv.Datetime, strconv.Itoa(inv.CustomerId), strconv.FormatFloat(inv.Total, 'f', -1, 64)}
+	}
+
+	err = writeCSV(s.pathCsv, invoicesCSVHeader, records)
+	return
+}
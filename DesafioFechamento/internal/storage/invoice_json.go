@@ -50,3 +50,27 @@ func (s *InvoicesStorage) FindAll() (i []internal.Invoice, err error) {
 
 	return
 }
+
+func (s *InvoicesStorage) SaveAll(i []internal.Invoice) (err error) {
+	// deserialize
+	invoicesJSON := make([]InvoicesJSON, len(i))
+	for ix, inv := range i {
+		invoicesJSON[ix] = InvoicesJSON{
+			Id:         inv.Id,
+			Datetime:   inv.Datetime,
+			CustomerId: inv.CustomerId,
+			Total:      inv.Total,
+		}
+	}
+
+	// create file
+	file, err := os.Create(s.pathJson)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// encode file
+	err = json.NewEncoder(file).Encode(invoicesJSON)
+	return
+}
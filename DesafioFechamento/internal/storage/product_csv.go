@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"app/internal"
+	"fmt"
+	"strconv"
+)
+
+// NewProductsStorageCSV creates a new ProductsStorageCSV reading and writing the csv file at path.
+func NewProductsStorageCSV(path string) *ProductsStorageCSV {
+	return &ProductsStorageCSV{pathCsv: path}
+}
+
+// ProductsStorageCSV is the csv implementation of internal.StorageProduct.
+// The first line of the file is the header with the same names of the json fields.
+type ProductsStorageCSV struct {
+	pathCsv string
+}
+
+// productsCSVHeader is the header of the products csv file.
+var productsCSVHeader = []string{"id", "description", "price"}
+
+// FindAll returns all products of the csv file.
+func (s *ProductsStorageCSV) FindAll() (p []internal.Product, err error) {
+	records, err := readCSV(s.pathCsv, productsCSVHeader)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	for ix, r := range records {
+		id, err := strconv.Atoi(r[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: id: %w", ix+2, err)
+		}
+		price, err := strconv.ParseFloat(r[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: price: %w", ix+2, err)
+		}
+		p = append(p, internal.Product{
+			Id: id,
+			ProductAttributes: internal.ProductAttributes{
+				Description: r[1],
+				Price:       price,
+			},
+		})
+	}
+
+	return
+}
+
+// SaveAll replaces the products of the csv file.
+func (s *ProductsStorageCSV) SaveAll(p []internal.Product) (err error) {
+	records := make([][]string, len(p))
+	for ix, prod := range p {
+		records[ix] = []string{strconv.Itoa(prod.Id), prod.Description, strconv.FormatFloat(prod.Price, 'f', -1, 64)}
+	}
+
+	err = writeCSV(s.pathCsv, productsCSVHeader, records)
+	return
+}
@@ -48,3 +48,26 @@ func (s *ProductsStorage) FindAll() (p []internal.Product, err error) {
 
 	return
 }
+
+func (s *ProductsStorage) SaveAll(p []internal.Product) (err error) {
+	// deserialize
+	productsJSON := make([]ProductsJSON, len(p))
+	for ix, prod := range p {
+		productsJSON[ix] = ProductsJSON{
+			Id:          prod.Id,
+			Description: prod.Description,
+			Price:       prod.Price,
+		}
+	}
+
+	// create file
+	file, err := os.Create(s.pathJson)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// encode file
+	err = json.NewEncoder(file).Encode(productsJSON)
+	return
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"app/internal"
+	"fmt"
+	"strconv"
+)
+
+// NewSalesStorageCSV creates a new SalesStorageCSV reading and writing the csv file at path.
+func NewSalesStorageCSV(path string) *SalesStorageCSV {
+	return &SalesStorageCSV{pathCsv: path}
+}
+
+// SalesStorageCSV is the csv implementation of internal.StorageSale.
+// The first line of the file is the header with the same names of the json fields.
+type SalesStorageCSV struct {
+	pathCsv string
+}
+
+// salesCSVHeader is the header of the sales csv file.
+var salesCSVHeader = []string{"id", "quantity", "product_id", "invoice_id"}
+
+// FindAll returns all sales of the csv file.
+func (s *SalesStorageCSV) FindAll() (l []internal.Sale, err error) {
+	records, err := readCSV(s.pathCsv, salesCSVHeader)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	for ix, r := range records {
+		id, err := strconv.Atoi(r[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: id: %w", ix+2, err)
+		}
+		quantity, err := strconv.Atoi(r[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: quantity: %w", ix+2, err)
+		}
+		productId, err := strconv.Atoi(r[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: product_id: %w", ix+2, err)
+		}
+		invoiceId, err := strconv.Atoi(r[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invoice_id: %w", ix+2, err)
+		}
+		l = append(l, internal.Sale{
+			Id: id,
+			SaleAttributes: internal.SaleAttributes{
+				Quantity:  quantity,
+				ProductId: productId,
+				InvoiceId: invoiceId,
+			},
+		})
+	}
+
+	return
+}
+
+// SaveAll replaces the sales of the csv file.
+func (s *SalesStorageCSV) SaveAll(l []internal.Sale) (err error) {
+	records := make([][]string, len(l))
+	for ix, sale := range l {
+		records[ix] = []string{strconv.Itoa(sale.Id), strconv.Itoa(sale.Quantity), strconv.Itoa(sale.ProductId), strconv.Itoa(sale.InvoiceId)}
+	}
+
+	err = writeCSV(s.pathCsv, salesCSVHeader, records)
+	return
+}
@@ -50,3 +50,27 @@ func (s *SalesStorage) FindAll() (l []internal.Sale, err error) {
 
 	return
 }
+
+func (s *SalesStorage) SaveAll(l []internal.Sale) (err error) {
+	// deserialize
+	salesJSON := make([]SalesJSON, len(l))
+	for ix, sale := range l {
+		salesJSON[ix] = SalesJSON{
+			Id:        sale.Id,
+			Quantity:  sale.Quantity,
+			ProductId: sale.ProductId,
+			InvoiceId: sale.InvoiceId,
+		}
+	}
+
+	// create file
+	file, err := os.Create(s.pathJson)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// encode file
+	err = json.NewEncoder(file).Encode(salesJSON)
+	return
+}
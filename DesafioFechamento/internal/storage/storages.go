@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"path/filepath"
+
+	"app/internal"
+)
+
+// StoragesFiles are the names of the files of each entity, without the extension.
+var StoragesFiles = []string{"customers", "products", "invoices", "sales"}
+
+// NewStorages returns the storages of every entity for the files of the given format in dir.
+func NewStorages(dir string, format internal.StorageFormat) (st internal.Storages, err error) {
+	path := func(name string) string {
+		return filepath.Join(dir, name+"."+string(format))
+	}
+
+	switch format {
+	case internal.StorageFormatJSON:
+		st = internal.Storages{
+			Customer: NewCustomersStorage(path("customers")),
+			Product:  NewProductsStorage(path("products")),
+			Invoice:  NewInvoicesStorage(path("invoices")),
+			Sale:     NewSalesStorage(path("sales")),
+		}
+	case internal.StorageFormatCSV:
+		st = internal.Storages{
+			Customer: NewCustomersStorageCSV(path("customers")),
+			Product:  NewProductsStorageCSV(path("products")),
+			Invoice:  NewInvoicesStorageCSV(path("invoices")),
+			Sale:     NewSalesStorageCSV(path("sales")),
+		}
+	default:
+		err = internal.ErrStorageFormatInvalid
+	}
+	return
+}
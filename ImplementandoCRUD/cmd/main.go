@@ -3,15 +3,17 @@ package main
 import (
 	"app/internal/application"
 	"fmt"
+	"os"
 )
 
 func main() {
 	// env
-	// ...
+	// - STORAGE: where the products are stored, mysql (default), json (./docs/db/json/products.json) or memory
+	storage := os.Getenv("STORAGE")
 
 	// app
 	// - config
-	app := application.NewApplicationDefault("", "./docs/db/json/products.json")
+	app := application.NewApplicationDefault("", storage, "./docs/db/json/products.json")
 	// - tear down
 	defer app.TearDown()
 	// - set up
@@ -1,9 +1,12 @@
 package application
 
 import (
+	"app/internal"
 	"app/internal/handler"
 	"app/internal/repository"
+	"app/internal/store"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 
@@ -12,18 +15,33 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
+const (
+	// StorageMySQL stores the products in the MySQL database.
+	StorageMySQL = "mysql"
+	// StorageJSON stores the products in the JSON file of filePathStore.
+	StorageJSON = "json"
+	// StorageMemory stores the products in memory, they are lost when the application stops.
+	StorageMemory = "memory"
+)
+
 // NewApplicationDefault creates a new default application.
-func NewApplicationDefault(addr, filePathStore string) (a *ApplicationDefault) {
+// The storage is StorageMySQL (default), StorageJSON or StorageMemory.
+func NewApplicationDefault(addr, storage, filePathStore string) (a *ApplicationDefault) {
 	// default config
 	defaultRouter := chi.NewRouter()
 	defaultAddr := ":8080"
 	if addr != "" {
 		defaultAddr = addr
 	}
+	defaultStorage := StorageMySQL
+	if storage != "" {
+		defaultStorage = storage
+	}
 
 	a = &ApplicationDefault{
 		rt:            defaultRouter,
 		addr:          defaultAddr,
+		storage:       defaultStorage,
 		filePathStore: filePathStore,
 	}
 	return
@@ -35,7 +53,9 @@ type ApplicationDefault struct {
 	rt *chi.Mux
 	// addr is the address to listen.
 	addr string
-	// filePathStore is the file path to store.
+	// storage is the backend of the repository.
+	storage string
+	// filePathStore is the file path to store the products with StorageJSON.
 	filePathStore string
 	// db is the database connection.
 	db *sql.DB
@@ -43,24 +63,52 @@ type ApplicationDefault struct {
 
 // TearDown tears down the application.
 func (a *ApplicationDefault) TearDown() (err error) {
+	if a.db == nil {
+		return
+	}
 	return a.db.Close()
 }
 
+// setUpRepository creates the repository on the storage backend.
+func (a *ApplicationDefault) setUpRepository() (rp internal.RepositoryProduct, err error) {
+	switch a.storage {
+	case StorageMySQL:
+		dsn := "user:user@tcp(127.0.0.1:3306)/my_db"
+		a.db, err = sql.Open("mysql", dsn)
+		if err != nil {
+			return
+		}
+
+		if err = a.db.Ping(); err != nil {
+			return
+		}
+
+		rp = repository.NewRepositoryProductDB(a.db)
+	case StorageJSON:
+		st := store.NewStoreProductJSON(a.filePathStore)
+
+		// - read the file now, so a missing or invalid one fails the set up
+		if _, err = st.ReadAll(); err != nil {
+			return
+		}
+
+		rp = repository.NewRepositoryProductStore(st)
+	case StorageMemory:
+		rp = repository.NewRepositoryProductStore(store.NewStoreProductMemory())
+	default:
+		err = fmt.Errorf("unknown storage %q, expected %s, %s or %s", a.storage, StorageMySQL, StorageJSON, StorageMemory)
+	}
+	return
+}
+
 // SetUp sets up the application.
 func (a *ApplicationDefault) SetUp() (err error) {
-	dsn := "user:user@tcp(127.0.0.1:3306)/my_db"
-	a.db, err = sql.Open("mysql", dsn)
+	// - repository
+	rp, err := a.setUpRepository()
 	if err != nil {
 		return err
 	}
 
-	if err = a.db.Ping(); err != nil {
-		return err
-	}
-
-	// - repository
-	rp := repository.NewRepositoryProductDB(a.db)
-
 	// - handler
 	hd := handler.NewHandlerProduct(rp)
 
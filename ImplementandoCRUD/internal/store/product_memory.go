@@ -0,0 +1,45 @@
+package store
+
+import (
+	"app/internal"
+	"maps"
+	"sync"
+)
+
+// NewStoreProductMemory creates a new empty store in memory for products.
+func NewStoreProductMemory() (s *StoreProductMemory) {
+	s = &StoreProductMemory{
+		p: make(map[int]internal.Product),
+	}
+	return
+}
+
+// StoreProductMemory is a store that keeps the products in memory, they are lost when the application stops.
+type StoreProductMemory struct {
+	// mu guards the products.
+	mu sync.Mutex
+	// p are the products by id.
+	p map[int]internal.Product
+}
+
+// ReadAll reads all products from the store.
+func (s *StoreProductMemory) ReadAll() (p map[int]internal.Product, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// callers change the map they get, so they get a copy
+	p = maps.Clone(s.p)
+	return
+}
+
+// WriteAll writes all products to the store.
+func (s *StoreProductMemory) WriteAll(p map[int]internal.Product) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.p = maps.Clone(p)
+	if s.p == nil {
+		s.p = make(map[int]internal.Product)
+	}
+	return
+}
@@ -3,15 +3,44 @@ package main
 import (
 	"app/internal/application"
 	"fmt"
+	"os"
+	"time"
 )
 
 func main() {
 	// env
-	// ...
+	// - STORAGE: where the data is stored, mysql (default), json (./docs/db/json) or memory
+	storage := os.Getenv("STORAGE")
+	// - SWEEP_EXPIRED_INTERVAL: how often expired products are unpublished (e.g. 1h), unset disables it
+	var sweepExpiredInterval time.Duration
+	if s := os.Getenv("SWEEP_EXPIRED_INTERVAL"); s != "" {
+		var err error
+		sweepExpiredInterval, err = time.ParseDuration(s)
+		if err != nil {
+			fmt.Println("invalid SWEEP_EXPIRED_INTERVAL:", err)
+			return
+		}
+	}
+
+	// - STORE_FLUSH_INTERVAL: how long the writes to the product file are batched (e.g. 1s), unset writes them at once
+	var storeFlushInterval time.Duration
+	if s := os.Getenv("STORE_FLUSH_INTERVAL"); s != "" {
+		var err error
+		storeFlushInterval, err = time.ParseDuration(s)
+		if err != nil {
+			fmt.Println("invalid STORE_FLUSH_INTERVAL:", err)
+			return
+		}
+	}
 
 	// app
 	// - config
-	app := application.NewApplicationDefault("", "./docs/db/json/products.json")
+	app := application.NewApplicationDefault(&application.ConfigApplicationDefault{
+		Storage:              storage,
+		FilePathStore:        "./docs/db/json/products.json",
+		SweepExpiredInterval: sweepExpiredInterval,
+		StoreFlushInterval:   storeFlushInterval,
+	})
 	// - tear down
 	defer app.TearDown()
 	// - set up
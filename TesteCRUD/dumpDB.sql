@@ -2,6 +2,7 @@ CREATE DATABASE `my_db3`;
 USE `my_db3`;
 
 -- Excluindo tabelas se existirem
+DROP TABLE IF EXISTS `transfers`;
 DROP TABLE IF EXISTS `products`;
 DROP TABLE IF EXISTS `warehouses`;
 
@@ -17,7 +18,7 @@ CREATE TABLE `warehouses` (
 
 -- Inserindo um registro na tabela `warehouses`
 INSERT INTO `warehouses` (`name`, `address`, `telephone`, `capacity`) VALUES
-('Main Warehouse', '221 Baker Street', '4555666', 100);
+('Main Warehouse', '221 Baker Street', '4555666', 150000);
 
 -- Criando a tabela `products`
 CREATE TABLE `products` (
@@ -29,7 +30,10 @@ CREATE TABLE `products` (
   `expiration` date DEFAULT NULL,
   `price` decimal(5,2) DEFAULT NULL,
   `id_warehouse` INT NOT NULL,
+  `version` int NOT NULL DEFAULT 1,
   PRIMARY KEY (`id`),
+  -- code_value é único por depósito: uma transferência parcial divide o produto em um por depósito com o mesmo code_value
+  UNIQUE KEY `code_value_warehouse` (`code_value`, `id_warehouse`),
   FOREIGN KEY (`id_warehouse`) REFERENCES `warehouses`(`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
@@ -44,4 +48,17 @@ INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expir
 ('Oil - Sunflower', 169, '59779-590', '0', '2022-07-03', 7.24, 1),
 ('Persimmons', 238, '45802-327', '0', '2021-04-14', 60.65, 1),
 ('Beer - Labatt Blue', 23, '48951-1215', '1', '2022-06-23', 32.99, 1),
-('Ranchero - Primerba, Paste', 59, '0268-1173', '1', '2021-04-02', 17.02, 1);
\ No newline at end of file
+('Ranchero - Primerba, Paste', 59, '0268-1173', '1', '2021-04-02', 17.02, 1);
+
+-- Criando a tabela `transfers`
+CREATE TABLE `transfers` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `code_value` varchar(50) NOT NULL,
+  `quantity` int NOT NULL,
+  `from_warehouse` int NOT NULL,
+  `to_warehouse` int NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_transfers_from_warehouse` (`from_warehouse`),
+  KEY `idx_transfers_to_warehouse` (`to_warehouse`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
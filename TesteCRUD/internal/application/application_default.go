@@ -1,30 +1,78 @@
 package application
 
 import (
+	"app/internal"
 	"app/internal/handler"
 	"app/internal/repository"
+	"app/internal/store"
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/go-sql-driver/mysql"
 )
 
+const (
+	// StorageMySQL stores the products, warehouses and transfers in the MySQL database.
+	StorageMySQL = "mysql"
+	// StorageJSON stores the products, warehouses and transfers in JSON files.
+	StorageJSON = "json"
+	// StorageMemory stores the products, warehouses and transfers in memory, they are lost when the application stops.
+	StorageMemory = "memory"
+)
+
+// ConfigApplicationDefault is the configuration of the default application.
+type ConfigApplicationDefault struct {
+	// Addr is the address to listen.
+	Addr string
+	// Storage is the backend of the repositories: StorageMySQL (default), StorageJSON or StorageMemory.
+	Storage string
+	// FilePathStore is the file path to store the products with StorageJSON.
+	// The warehouses and the transfers are stored in warehouses.json and transfers.json next to it.
+	FilePathStore string
+	// SweepExpiredInterval is how often expired products are unpublished, zero disables it.
+	SweepExpiredInterval time.Duration
+	// StoreFlushInterval is how long the writes to the product store wait before reaching the file, zero writes them at once.
+	StoreFlushInterval time.Duration
+}
+
 // NewApplicationDefault creates a new default application.
-func NewApplicationDefault(addr, filePathStore string) (a *ApplicationDefault) {
+func NewApplicationDefault(cfg *ConfigApplicationDefault) (a *ApplicationDefault) {
 	// default config
 	defaultRouter := chi.NewRouter()
-	defaultAddr := ":8080"
-	if addr != "" {
-		defaultAddr = addr
+	defaultCfg := ConfigApplicationDefault{
+		Addr:    ":8080",
+		Storage: StorageMySQL,
+	}
+	if cfg != nil {
+		if cfg.Addr != "" {
+			defaultCfg.Addr = cfg.Addr
+		}
+		if cfg.Storage != "" {
+			defaultCfg.Storage = cfg.Storage
+		}
+		defaultCfg.FilePathStore = cfg.FilePathStore
+		defaultCfg.SweepExpiredInterval = cfg.SweepExpiredInterval
+		defaultCfg.StoreFlushInterval = cfg.StoreFlushInterval
 	}
 
 	a = &ApplicationDefault{
-		rt:            defaultRouter,
-		addr:          defaultAddr,
-		filePathStore: filePathStore,
+		rt:                   defaultRouter,
+		addr:                 defaultCfg.Addr,
+		storage:              defaultCfg.Storage,
+		filePathStore:        defaultCfg.FilePathStore,
+		sweepExpiredInterval: defaultCfg.SweepExpiredInterval,
+		storeFlushInterval:   defaultCfg.StoreFlushInterval,
+		stop:                 make(chan struct{}),
 	}
 	return
 }
@@ -35,34 +83,126 @@ type ApplicationDefault struct {
 	rt *chi.Mux
 	// addr is the address to listen.
 	addr string
-	// filePathStore is the file path to store.
+	// storage is the backend of the repositories.
+	storage string
+	// filePathStore is the file path to store the products with StorageJSON.
 	filePathStore string
+	// sweepExpiredInterval is how often expired products are unpublished, zero disables it.
+	sweepExpiredInterval time.Duration
+	// storeFlushInterval is how long the writes to the product store wait before reaching the file, zero writes them at once.
+	storeFlushInterval time.Duration
+	// stop stops the background tasks.
+	stop chan struct{}
 	// db is the database connection.
 	db *sql.DB
+	// stProd is the cached product store, nil when the products are not stored in a file.
+	stProd *store.StoreProductCache
 }
 
 // TearDown tears down the application.
+// The pending writes of the product store are flushed, so no write is lost at shutdown.
 func (a *ApplicationDefault) TearDown() (err error) {
-	return a.db.Close()
+	close(a.stop)
+	if a.stProd != nil {
+		if errFlush := a.stProd.Flush(); errFlush != nil {
+			log.Println("flush product store:", errFlush)
+			err = errFlush
+		}
+	}
+	if a.db == nil {
+		return
+	}
+	if errClose := a.db.Close(); errClose != nil && err == nil {
+		err = errClose
+	}
+	return
+}
+
+// sweepExpired unpublishes the expired products now and then every interval, until the application is torn down.
+func (a *ApplicationDefault) sweepExpired(rp internal.RepositoryProduct, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := rp.UnpublishExpired(internal.Today())
+		switch {
+		case err != nil:
+			log.Println("sweep expired products:", err)
+		case n > 0:
+			log.Println("sweep expired products: unpublished", n)
+		}
+
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setUpRepositories creates the repositories on the storage backend.
+func (a *ApplicationDefault) setUpRepositories() (rpProd internal.RepositoryProduct, rpWare internal.RepositoryWarehouse, rpTransfer internal.RepositoryTransfer, err error) {
+	switch a.storage {
+	case StorageMySQL:
+		dsn := "root:root@tcp(127.0.0.1:3308)/my_db3"
+		a.db, err = sql.Open("mysql", dsn)
+		if err != nil {
+			return
+		}
+
+		if err = a.db.Ping(); err != nil {
+			return
+		}
+
+		rpWare = repository.NewRepositoryWarehouseDB(a.db)
+		rpTransfer = repository.NewRepositoryTransferDB(a.db)
+		rpProd = repository.NewRepositoryProductDB(a.db)
+	case StorageJSON:
+		dir := filepath.Dir(a.filePathStore)
+		a.stProd = store.NewStoreProductCache(store.NewStoreProductJSON(a.filePathStore), a.storeFlushInterval)
+		stWare := store.NewStoreWarehouseJSON(filepath.Join(dir, "warehouses.json"))
+		stTransfer := store.NewStoreTransferJSON(filepath.Join(dir, "transfers.json"))
+
+		// - read the files now, so a missing or invalid one fails the set up
+		if _, err = a.stProd.ReadAll(); err != nil {
+			return
+		}
+		if _, err = stWare.ReadAll(); err != nil {
+			return
+		}
+		if _, err = stTransfer.ReadAll(); err != nil {
+			return
+		}
+
+		rpWare = repository.NewRepositoryWarehouseStore(stWare, a.stProd)
+		rpTransfer = repository.NewRepositoryTransferStore(stTransfer, a.stProd, rpWare)
+		rpProd = repository.NewRepositoryProductStore(a.stProd, rpWare)
+	case StorageMemory:
+		stProd := store.NewStoreMemory[internal.Product]()
+		rpWare = repository.NewRepositoryWarehouseStore(store.NewStoreMemory[internal.Warehouse](), stProd)
+		rpTransfer = repository.NewRepositoryTransferStore(store.NewStoreMemory[internal.Transfer](), stProd, rpWare)
+		rpProd = repository.NewRepositoryProductStore(stProd, rpWare)
+	default:
+		err = fmt.Errorf("unknown storage %q, expected %s, %s or %s", a.storage, StorageMySQL, StorageJSON, StorageMemory)
+	}
+	return
 }
 
 // SetUp sets up the application.
 func (a *ApplicationDefault) SetUp() (err error) {
-	dsn := "root:root@tcp(127.0.0.1:3308)/my_db3"
-	a.db, err = sql.Open("mysql", dsn)
+	rpProd, rpWare, rpTransfer, err := a.setUpRepositories()
 	if err != nil {
 		return err
 	}
 
-	if err = a.db.Ping(); err != nil {
-		return err
-	}
-
-	rpWare := repository.NewRepositoryWarehouseDB(a.db)
 	hdWare := handler.NewHandlerWarehouse(rpWare)
+	hdTransfer := handler.NewHandlerTransfer(rpTransfer, rpWare)
+	hdProd := handler.NewHandlerProduct(rpProd)
 
-	rpProd := repository.NewRepositoryProductDB(a.db)
-	hdProd := handler.NewHandlerProduct(rpProd, rpWare)
+	// background tasks
+	if a.sweepExpiredInterval > 0 {
+		go a.sweepExpired(rpProd, a.sweepExpiredInterval)
+	}
 
 	// router
 	// - middlewares
@@ -72,10 +212,15 @@ func (a *ApplicationDefault) SetUp() (err error) {
 	a.rt.Route("/products", func(r chi.Router) {
 		// GET /products/{id}
 		r.Get("/", hdProd.GetAll())
+		r.Get("/expiring", hdProd.GetExpiring())
+		r.Get("/expired", hdProd.GetExpired())
+		r.Get("/code/{code_value}", hdProd.GetByCodeValue())
+		r.Get("/export", hdProd.Export())
 		r.Get("/{id}", hdProd.GetById())
-		r.Get("/warehouse/reportProducts", hdProd.GetReportProductsById())
 		// POST /products
 		r.Post("/", hdProd.Create())
+		// POST /products/bulk
+		r.Post("/bulk", hdProd.Bulk())
 		// PUT /products/{id}
 		r.Put("/{id}", hdProd.UpdateOrCreate())
 		// PATCH /products/{id}
@@ -86,16 +231,36 @@ func (a *ApplicationDefault) SetUp() (err error) {
 
 	a.rt.Route("/warehouse", func(r chi.Router) {
 		r.Get("/", hdWare.GetAll())
+		r.Get("/report", hdWare.GetReportAll())
+		r.Get("/{id}/report", hdWare.GetReport())
 		r.Get("/{id}", hdWare.GetById())
 		r.Post("/", hdWare.Create())
+		r.Put("/{id}", hdWare.Update())
+		r.Patch("/{id}", hdWare.Patch())
+		r.Delete("/{id}", hdWare.Delete())
+		r.Get("/{id}/transfers", hdTransfer.GetByWarehouse())
+		r.Post("/{id}/transfers", hdTransfer.Create())
 	})
 
 	return
 }
 
-// Run runs the application.
+// Run runs the application until it gets an interrupt or terminate signal.
+// The requests in flight are then finished, so TearDown can flush their writes.
 func (a *ApplicationDefault) Run() (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: a.addr, Handler: a.rt}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
 	log.Println("Server is running on", a.addr)
-	err = http.ListenAndServe(a.addr, a.rt)
+	err = srv.ListenAndServe()
+	if err == http.ErrServerClosed {
+		err = nil
+	}
 	return
 }
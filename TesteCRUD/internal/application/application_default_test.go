@@ -0,0 +1,64 @@
+package application
+
+import (
+	"app/internal"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// repositoryProductSweepStub is a product repository counting the sweeps.
+type repositoryProductSweepStub struct {
+	internal.RepositoryProduct
+	// sweeps is the number of calls to UnpublishExpired.
+	sweeps atomic.Int32
+}
+
+func (r *repositoryProductSweepStub) UnpublishExpired(today time.Time) (n int, err error) {
+	r.sweeps.Add(1)
+	return
+}
+
+// Tests for ApplicationDefault.sweepExpired
+func TestApplicationDefault_SweepExpired(t *testing.T) {
+	t.Run("success - sweeps every interval until torn down", func(t *testing.T) {
+		// arrange
+		a := NewApplicationDefault(&ConfigApplicationDefault{Storage: StorageMemory})
+		rp := &repositoryProductSweepStub{}
+		done := make(chan struct{})
+
+		// act
+		go func() {
+			a.sweepExpired(rp, time.Millisecond)
+			close(done)
+		}()
+		require.Eventually(t, func() bool { return rp.sweeps.Load() >= 3 }, time.Second, time.Millisecond)
+		err := a.TearDown()
+
+		// assert
+		require.NoError(t, err)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("sweeper not stopped by TearDown")
+		}
+		n := rp.sweeps.Load()
+		time.Sleep(10 * time.Millisecond)
+		require.Equal(t, n, rp.sweeps.Load())
+	})
+
+	t.Run("success - set up starts it and tear down stops it", func(t *testing.T) {
+		// arrange
+		a := NewApplicationDefault(&ConfigApplicationDefault{Storage: StorageMemory, SweepExpiredInterval: time.Millisecond})
+
+		// act
+		err := a.SetUp()
+		require.NoError(t, err)
+		err = a.TearDown()
+
+		// assert
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// productETag returns the entity tag of a version of a product.
+func productETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// etagMatches reports whether the list of entity tags of an If-Match or If-None-Match header matches etag.
+// If-Match compares strongly, so weak tags never match, and If-None-Match compares weakly.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" {
+			return true
+		}
+		if strings.HasPrefix(t, "W/") {
+			if !weak {
+				continue
+			}
+			t = t[2:]
+		}
+		if t == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch checks the If-Match header of a write matches the version of the product,
+// writing 428 when the header is missing and 412 when it does not match.
+// It returns whether the write can go on.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, version int) bool {
+	ifMatch := r.Header.Get("If-Match")
+	switch {
+	case ifMatch == "":
+		response.JSON(w, http.StatusPreconditionRequired, "If-Match header is required")
+		return false
+	case !etagMatches(ifMatch, productETag(version), false):
+		w.Header().Set("ETag", productETag(version))
+		response.JSON(w, http.StatusPreconditionFailed, "product was modified, get it again")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"app/internal/handler"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for the If-None-Match header of HandlerProduct.GetById
+func TestHandlerProduct_GetById_IfNoneMatch(t *testing.T) {
+	cases := []struct {
+		name        string
+		ifNoneMatch string
+		code        int
+	}{
+		{name: "success - no header", ifNoneMatch: "", code: http.StatusOK},
+		{name: "success - strong tag matching", ifNoneMatch: `"3"`, code: http.StatusNotModified},
+		{name: "success - weak tag matching", ifNoneMatch: `W/"3"`, code: http.StatusNotModified},
+		{name: "success - list with a tag matching", ifNoneMatch: `"1", W/"3"`, code: http.StatusNotModified},
+		{name: "success - any tag", ifNoneMatch: "*", code: http.StatusNotModified},
+		{name: "success - tag not matching", ifNoneMatch: `"2"`, code: http.StatusOK},
+		{name: "success - unquoted tag not matching", ifNoneMatch: `3`, code: http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			p := productExpiring(1, 10)
+			p.Version = 3
+			hd, _ := newHandlerProductMemory(t, p)
+			req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+			if c.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", c.ifNoneMatch)
+			}
+			req = withURLParam(req, "id", "1")
+			res := httptest.NewRecorder()
+
+			// act
+			hd.GetById()(res, req)
+
+			// assert
+			require.Equal(t, c.code, res.Code)
+			require.Equal(t, `"3"`, res.Header().Get("ETag"))
+		})
+	}
+}
+
+// Tests for the If-Match header of the product writes
+func TestHandlerProduct_IfMatch(t *testing.T) {
+	body := `{"name":"Corn Shoots","quantity":1,"code_value":"code-1","is_published":true,"expiration":"2099-01-01","price":1.5,"id_warehouse":1}`
+
+	cases := []struct {
+		name    string
+		ifMatch string
+		code    int
+		message string
+	}{
+		{name: "success - strong tag matching", ifMatch: `"3"`, code: http.StatusOK},
+		{name: "success - list with a tag matching", ifMatch: `"1", "3"`, code: http.StatusOK},
+		{name: "success - any tag", ifMatch: "*", code: http.StatusOK},
+		{name: "error - no header", ifMatch: "", code: http.StatusPreconditionRequired, message: `"If-Match header is required"`},
+		{name: "error - tag not matching", ifMatch: `"2"`, code: http.StatusPreconditionFailed, message: `"product was modified, get it again"`},
+		{name: "error - weak tag matching", ifMatch: `W/"3"`, code: http.StatusPreconditionFailed, message: `"product was modified, get it again"`},
+	}
+	writes := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		handler     func(hd *handler.HandlerProduct) http.HandlerFunc
+	}{
+		{name: "put", method: http.MethodPut, contentType: "application/json", body: body, handler: (*handler.HandlerProduct).UpdateOrCreate},
+		{name: "patch", method: http.MethodPatch, contentType: "application/merge-patch+json", body: `{"price":2.5}`, handler: (*handler.HandlerProduct).Update},
+		{name: "delete", method: http.MethodDelete, handler: (*handler.HandlerProduct).Delete},
+	}
+	for _, wr := range writes {
+		for _, c := range cases {
+			t.Run(c.name+" - "+wr.name, func(t *testing.T) {
+				// arrange
+				p := productExpiring(1, 10)
+				p.Version = 3
+				hd, st := newHandlerProductMemory(t, p)
+				req := httptest.NewRequest(wr.method, "/products/1", strings.NewReader(wr.body))
+				if wr.contentType != "" {
+					req.Header.Set("Content-Type", wr.contentType)
+				}
+				if c.ifMatch != "" {
+					req.Header.Set("If-Match", c.ifMatch)
+				}
+				req = withURLParam(req, "id", "1")
+				res := httptest.NewRecorder()
+
+				// act
+				wr.handler(hd)(res, req)
+
+				// assert
+				if c.code == http.StatusOK && wr.method == http.MethodDelete {
+					require.Equal(t, http.StatusNoContent, res.Code)
+					return
+				}
+				require.Equal(t, c.code, res.Code)
+				if c.message == "" {
+					require.Equal(t, `"4"`, res.Header().Get("ETag"))
+					return
+				}
+				require.JSONEq(t, c.message, res.Body.String())
+				if c.code == http.StatusPreconditionFailed {
+					require.Equal(t, `"3"`, res.Header().Get("ETag"))
+				}
+				ps, err := st.ReadAll()
+				require.NoError(t, err)
+				require.Equal(t, p, ps[1])
+			})
+		}
+	}
+
+	t.Run("error - put creating with a tag", func(t *testing.T) {
+		// arrange
+		hd, st := newHandlerProductMemory(t)
+		req := httptest.NewRequest(http.MethodPut, "/products/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		req = withURLParam(req, "id", "1")
+		res := httptest.NewRecorder()
+
+		// act
+		hd.UpdateOrCreate()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		require.JSONEq(t, `"product not found"`, res.Body.String())
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
+}
@@ -4,19 +4,24 @@ import (
 	"app/internal"
 	"app/platform/web/request"
 	"app/platform/web/response"
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
 // NewHandlerProduct creates a new handler for products.
-func NewHandlerProduct(rpP internal.RepositoryProduct, rpW internal.RepositoryWarehouse) (h *HandlerProduct) {
+func NewHandlerProduct(rpP internal.RepositoryProduct) (h *HandlerProduct) {
 	h = &HandlerProduct{
 		rpProd: rpP,
-		rpWare: rpW,
 	}
 	return
 }
@@ -25,7 +30,6 @@ func NewHandlerProduct(rpP internal.RepositoryProduct, rpW internal.RepositoryWa
 type HandlerProduct struct {
 	// rp is the repository for products.
 	rpProd internal.RepositoryProduct
-	rpWare internal.RepositoryWarehouse
 }
 
 // ProductJSON is a product in JSON format.
@@ -40,37 +44,234 @@ type ProductJSON struct {
 	IdWarehouse int     `json:"id_warehouse"`
 }
 
+// PageJSON is the pagination metadata of a list in JSON format.
+type PageJSON struct {
+	Page       int     `json:"page"`
+	PageSize   int     `json:"page_size"`
+	Total      int     `json:"total"`
+	TotalPages int     `json:"total_pages"`
+	Next       *string `json:"next"`
+}
+
+const (
+	// defaultPageSize is the page size used when page_size is not set.
+	defaultPageSize = 20
+	// maxPageSize is the maximum page size accepted.
+	maxPageSize = 100
+)
+
+// productQuery reads the filters, sorting and pagination of a product search from the query parameters:
+// page, page_size, sort (a ProductJSON field, prefixed with - for descending order), is_published,
+// id_warehouse, price_min, price_max, expiration_from and expiration_to.
+func productQuery(r *http.Request) (q internal.ProductQuery, err error) {
+	v := r.URL.Query()
+	q = internal.ProductQuery{Sort: "id", Page: 1, PageSize: defaultPageSize}
+
+	// pagination
+	if s := v.Get("page"); s != "" {
+		q.Page, err = strconv.Atoi(s)
+		if err != nil || q.Page < 1 {
+			return q, errors.New("invalid page")
+		}
+	}
+	if s := v.Get("page_size"); s != "" {
+		q.PageSize, err = strconv.Atoi(s)
+		if err != nil || q.PageSize < 1 || q.PageSize > maxPageSize {
+			return q, fmt.Errorf("invalid page_size, must be between 1 and %d", maxPageSize)
+		}
+	}
+
+	// sorting
+	if s := v.Get("sort"); s != "" {
+		q.Sort, q.Desc = strings.CutPrefix(s, "-")
+		if !slices.Contains(internal.ProductSortFields, q.Sort) {
+			return q, fmt.Errorf("invalid sort, must be one of %s", strings.Join(internal.ProductSortFields, ", "))
+		}
+	}
+
+	// filters
+	if s := v.Get("is_published"); s != "" {
+		b, e := strconv.ParseBool(s)
+		if e != nil {
+			return q, errors.New("invalid is_published")
+		}
+		q.IsPublished = &b
+	}
+	if s := v.Get("id_warehouse"); s != "" {
+		id, e := strconv.Atoi(s)
+		if e != nil {
+			return q, errors.New("invalid id_warehouse")
+		}
+		q.IdWarehouse = &id
+	}
+	if s := v.Get("price_min"); s != "" {
+		f, e := strconv.ParseFloat(s, 64)
+		if e != nil {
+			return q, errors.New("invalid price_min")
+		}
+		q.PriceMin = &f
+	}
+	if s := v.Get("price_max"); s != "" {
+		f, e := strconv.ParseFloat(s, 64)
+		if e != nil {
+			return q, errors.New("invalid price_max")
+		}
+		q.PriceMax = &f
+	}
+	if s := v.Get("expiration_from"); s != "" {
+		q.ExpirationFrom, err = time.Parse(time.DateOnly, s)
+		if err != nil {
+			return q, errors.New("invalid expiration_from")
+		}
+	}
+	if s := v.Get("expiration_to"); s != "" {
+		q.ExpirationTo, err = time.Parse(time.DateOnly, s)
+		if err != nil {
+			return q, errors.New("invalid expiration_to")
+		}
+	}
+
+	return q, nil
+}
+
+// GetAll gets a page of products, see productQuery for the query parameters.
 func (h *HandlerProduct) GetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// process
-		// - find all products
-		products, err := h.rpProd.FindAll()
+		// request
+		// - query parameters
+		q, err := productQuery(r)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error: "+err.Error())
+			response.JSON(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// response
-		// - serialize products to JSON
-		var productResponses []ProductJSON
-		for _, p := range products {
-			productResponses = append(productResponses, ProductJSON{
-				Id:          p.Id,
-				Name:        p.Name,
-				Quantity:    p.Quantity,
-				CodeValue:   p.CodeValue,
-				IsPublished: p.IsPublished,
-				Expiration:  p.Expiration.Format(time.DateOnly),
-				Price:       p.Price,
-				IdWarehouse: p.IdWarehouse,
-			})
+		// process and response
+		h.responsePage(w, r, q)
+	}
+}
+
+// defaultExpiringWithin is the period ahead a product counts as expiring when within is not set.
+const defaultExpiringWithin = "30d"
+
+// parseWithin parses a period of days, as 30 or 30d, or weeks, as 4w.
+func parseWithin(s string) (days int, err error) {
+	unit := 1
+	switch {
+	case strings.HasSuffix(s, "d"):
+		s = strings.TrimSuffix(s, "d")
+	case strings.HasSuffix(s, "w"):
+		s, unit = strings.TrimSuffix(s, "w"), 7
+	}
+	days, err = strconv.Atoi(s)
+	if err != nil || days < 0 {
+		return 0, errors.New("invalid within, must be a number of days (30d) or weeks (4w)")
+	}
+	return days * unit, nil
+}
+
+// GetExpiring gets a page of the products expiring from today up to the within query parameter
+// (default 30d), sorted by expiration. The other parameters are the ones of GetAll.
+func (h *HandlerProduct) GetExpiring() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: within
+		within := r.URL.Query().Get("within")
+		if within == "" {
+			within = defaultExpiringWithin
+		}
+		days, err := parseWithin(within)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// - query parameters
+		q, err := productQuery(r)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if r.URL.Query().Get("sort") == "" {
+			q.Sort = "expiration"
 		}
+		q.ExpirationFrom = internal.Today()
+		q.ExpirationTo = q.ExpirationFrom.AddDate(0, 0, days)
 
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    productResponses,
+		// process and response
+		h.responsePage(w, r, q)
+	}
+}
+
+// GetExpired gets a page of the products expired before today, sorted by expiration.
+// The other parameters are the ones of GetAll.
+func (h *HandlerProduct) GetExpired() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameters
+		q, err := productQuery(r)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if r.URL.Query().Get("sort") == "" {
+			q.Sort = "expiration"
+		}
+		q.ExpirationFrom = time.Time{}
+		q.ExpirationTo = internal.Today().AddDate(0, 0, -1)
+
+		// process and response
+		h.responsePage(w, r, q)
+	}
+}
+
+// responsePage finds a page of the products matching q and writes it with its pagination metadata.
+func (h *HandlerProduct) responsePage(w http.ResponseWriter, r *http.Request, q internal.ProductQuery) {
+	// process
+	// - find a page of products
+	products, total, err := h.rpProd.FindPage(q)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrRepositoryProductQueryInvalid):
+			response.JSON(w, http.StatusBadRequest, "invalid query")
+		default:
+			response.JSON(w, http.StatusInternalServerError, "internal server error: "+err.Error())
+		}
+		return
+	}
+
+	// response
+	// - pagination metadata
+	page := PageJSON{
+		Page:       q.Page,
+		PageSize:   q.PageSize,
+		Total:      total,
+		TotalPages: (total + q.PageSize - 1) / q.PageSize,
+	}
+	if q.Page < page.TotalPages {
+		v := r.URL.Query()
+		v.Set("page", strconv.Itoa(q.Page+1))
+		next := r.URL.Path + "?" + v.Encode()
+		page.Next = &next
+	}
+	// - serialize products to JSON
+	productResponses := []ProductJSON{}
+	for _, p := range products {
+		productResponses = append(productResponses, ProductJSON{
+			Id:          p.Id,
+			Name:        p.Name,
+			Quantity:    p.Quantity,
+			CodeValue:   p.CodeValue,
+			IsPublished: p.IsPublished,
+			Expiration:  p.Expiration.Format(time.DateOnly),
+			Price:       p.Price,
+			IdWarehouse: p.IdWarehouse,
 		})
 	}
+
+	response.JSON(w, http.StatusOK, map[string]any{
+		"message": "success",
+		"data":    productResponses,
+		"meta":    page,
+	})
 }
 
 // GetById gets a product by id.
@@ -98,6 +299,13 @@ func (h *HandlerProduct) GetById() http.HandlerFunc {
 		}
 
 		// response
+		// - entity tag, not modified when it matches If-None-Match
+		etag := productETag(p.Version)
+		w.Header().Set("ETag", etag)
+		if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+			response.JSON(w, http.StatusNotModified, nil)
+			return
+		}
 		// - serialize product to JSON
 		data := ProductJSON{
 			Id:          p.Id,
@@ -116,40 +324,83 @@ func (h *HandlerProduct) GetById() http.HandlerFunc {
 	}
 }
 
-func (h *HandlerProduct) GetReportProductsById() http.HandlerFunc {
+// GetByCodeValue gets the products with a code value, one per warehouse holding stock of it.
+func (h *HandlerProduct) GetByCodeValue() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.Atoi(r.URL.Query().Get("id"))
-		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid id")
-			return
-		}
+		// request
+		// - path parameter: code_value
+		code := chi.URLParam(r, "code_value")
 
 		// process
-		// - find warehouse by id
-		wh, err := h.rpWare.FindById(id)
+		// - find products by code value
+		ps, err := h.rpProd.FindByCodeValue(code)
 		if err != nil {
 			switch {
-			case errors.Is(err, internal.ErrRepositoryWarehouseNotFound):
-				response.JSON(w, http.StatusNotFound, "warehouse not found")
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				response.JSON(w, http.StatusNotFound, "product not found")
 			default:
 				response.JSON(w, http.StatusInternalServerError, "internal server error")
 			}
 			return
 		}
 
-		count, err := h.rpProd.CountProductsByWarehouseID(id)
-		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error")
-		}
-
 		// response
+		// - serialize products to JSON
+		data := make([]ProductJSON, len(ps))
+		for i, p := range ps {
+			data[i] = ProductJSON{
+				Id:          p.Id,
+				Name:        p.Name,
+				Quantity:    p.Quantity,
+				CodeValue:   p.CodeValue,
+				IsPublished: p.IsPublished,
+				Expiration:  p.Expiration.Format(time.DateOnly),
+				Price:       p.Price,
+				IdWarehouse: p.IdWarehouse,
+			}
+		}
 		response.JSON(w, http.StatusOK, map[string]any{
-			"name": wh.Name,
-			"data": count,
+			"message": "success",
+			"data":    data,
 		})
 	}
 }
 
+// responseProductWriteError writes the response of a failed product write.
+func responseProductWriteError(w http.ResponseWriter, err error) {
+	code, body := productWriteError(err)
+	response.JSON(w, code, body)
+}
+
+// productWriteError returns the status code and the body of a failed product write.
+// A missing or full warehouse or a duplicated code value is a conflict, and a full warehouse reports the remaining capacity.
+// A product written by someone else since it was read fails the precondition of the write,
+// and a store modified outside the service is a conflict the next attempt reads past.
+func productWriteError(err error) (code int, body any) {
+	var errCapacity *internal.WarehouseCapacityError
+	switch {
+	case errors.As(err, &errCapacity):
+		return http.StatusConflict, map[string]any{
+			"message":            "warehouse capacity exceeded",
+			"id_warehouse":       errCapacity.IdWarehouse,
+			"capacity":           errCapacity.Capacity,
+			"remaining_capacity": errCapacity.Remaining(),
+		}
+	case errors.Is(err, internal.ErrRepositoryWarehouseNotFound):
+		return http.StatusConflict, "warehouse not found"
+	case errors.Is(err, internal.ErrRepositoryProductDuplicated):
+		return http.StatusConflict, "code_value already exists in the warehouse"
+	case errors.Is(err, internal.ErrRepositoryProductVersionMismatch):
+		return http.StatusPreconditionFailed, "product was modified, get it again"
+	case errors.Is(err, internal.ErrRepositoryProductNotFound):
+		return http.StatusNotFound, "product not found"
+	case errors.Is(err, internal.ErrStoreProductModified):
+		return http.StatusConflict, "products were modified outside the service, try again"
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
+
 // RequestBodyProductCreate is a request body for creating a product.
 type RequestBodyProductCreate struct {
 	Name        string  `json:"name"`
@@ -162,6 +413,7 @@ type RequestBodyProductCreate struct {
 }
 
 // Create creates a product.
+// Its code value must be new in its warehouse, other warehouses can hold stock of the same code value.
 func (h *HandlerProduct) Create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
@@ -172,10 +424,17 @@ func (h *HandlerProduct) Create() http.HandlerFunc {
 			response.JSON(w, http.StatusBadRequest, "invalid body")
 			return
 		}
-		// - expiration
-		exp, err := time.Parse(time.DateOnly, body.Expiration)
-		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid expiration")
+		// - validate fields
+		if errs := body.Validate(); len(errs) > 0 {
+			responseValidationError(w, errs)
+			return
+		}
+		// - expiration, its format is validated above
+		exp, _ := time.Parse(time.DateOnly, body.Expiration)
+		// - query parameter: force, creates an already expired product
+		force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+		if exp.Before(internal.Today()) && !force {
+			response.JSON(w, http.StatusUnprocessableEntity, "expiration is in the past, set force=true to create it anyway")
 			return
 		}
 
@@ -194,7 +453,7 @@ func (h *HandlerProduct) Create() http.HandlerFunc {
 		}
 		err = h.rpProd.Save(&p)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			responseProductWriteError(w, err)
 			return
 		}
 
@@ -210,6 +469,7 @@ func (h *HandlerProduct) Create() http.HandlerFunc {
 			Price:       p.Price,
 			IdWarehouse: p.IdWarehouse,
 		}
+		w.Header().Set("ETag", productETag(p.Version))
 		response.JSON(w, http.StatusCreated, map[string]any{
 			"message": "success",
 			"data":    data,
@@ -218,6 +478,8 @@ func (h *HandlerProduct) Create() http.HandlerFunc {
 }
 
 // UpdateOrCreate updates or creates a product.
+// Replacing an existing product requires an If-Match header matching its ETag.
+// Creating a product with a past expiration requires force=true, as in Create.
 func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
@@ -234,14 +496,39 @@ func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 			response.JSON(w, http.StatusBadRequest, "invalid body")
 			return
 		}
-		// - expiration
-		exp, err := time.Parse(time.DateOnly, body.Expiration)
-		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid expiration")
+		// - validate fields
+		if errs := body.Validate(); len(errs) > 0 {
+			responseValidationError(w, errs)
 			return
 		}
+		// - expiration, its format is validated above
+		exp, _ := time.Parse(time.DateOnly, body.Expiration)
 
 		// process
+		// - check version, an existing product is only replaced at the version of If-Match
+		var version int
+		current, err := h.rpProd.FindById(id)
+		switch {
+		case err == nil:
+			if !checkIfMatch(w, r, current.Version) {
+				return
+			}
+			version = current.Version
+		case errors.Is(err, internal.ErrRepositoryProductNotFound):
+			if r.Header.Get("If-Match") != "" {
+				response.JSON(w, http.StatusPreconditionFailed, "product not found")
+				return
+			}
+			// - query parameter: force, creates an already expired product
+			force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+			if exp.Before(internal.Today()) && !force {
+				response.JSON(w, http.StatusUnprocessableEntity, "expiration is in the past, set force=true to create it anyway")
+				return
+			}
+		default:
+			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
 		// - update or save product
 		p := internal.Product{
 			Id: id,
@@ -254,10 +541,11 @@ func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 				Price:       body.Price,
 			},
 			IdWarehouse: body.IdWarehouse,
+			Version:     version,
 		}
 		err = h.rpProd.UpdateOrSave(&p)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			responseProductWriteError(w, err)
 			return
 		}
 
@@ -273,6 +561,7 @@ func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 			Price:       p.Price,
 			IdWarehouse: p.IdWarehouse,
 		}
+		w.Header().Set("ETag", productETag(p.Version))
 		response.JSON(w, http.StatusOK, map[string]any{
 			"message": "success",
 			"data":    data,
@@ -280,7 +569,10 @@ func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 	}
 }
 
-// Update updates a product.
+// Update patches a product.
+// The If-Match header must match the ETag of the product, so concurrent patches cannot overwrite each other.
+// The body is a JSON merge patch (RFC 7396) or a JSON patch (RFC 6902) of the product,
+// and the patched product is validated as a whole before the update.
 func (h *HandlerProduct) Update() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
@@ -290,6 +582,13 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 			response.JSON(w, http.StatusBadRequest, "invalid id")
 			return
 		}
+		// - content type
+		_, err = request.PatchContentType(r)
+		if err != nil {
+			w.Header().Set("Accept-Patch", request.ContentTypeMergePatch+", "+request.ContentTypeJSONPatch)
+			response.JSON(w, http.StatusUnsupportedMediaType, "content type must be "+request.ContentTypeMergePatch+" or "+request.ContentTypeJSONPatch)
+			return
+		}
 
 		// process
 		// - find product by id
@@ -303,8 +602,12 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 			}
 			return
 		}
+		// - check version
+		if !checkIfMatch(w, r, p.Version) {
+			return
+		}
 		// - patch product
-		body := RequestBodyProductCreate{
+		doc, err := json.Marshal(RequestBodyProductCreate{
 			Name:        p.Name,
 			Quantity:    p.Quantity,
 			CodeValue:   p.CodeValue,
@@ -312,18 +615,35 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 			Expiration:  p.Expiration.Format(time.DateOnly),
 			Price:       p.Price,
 			IdWarehouse: p.IdWarehouse,
-		}
-		err = request.JSON(r, &body)
+		})
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid body")
+			response.JSON(w, http.StatusInternalServerError, "internal server error")
 			return
 		}
-		// - expiration
-		exp, err := time.Parse(time.DateOnly, body.Expiration)
+		patched, err := request.Patch(r, doc)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid expiration")
+			switch {
+			case errors.Is(err, request.ErrRequestPatchTestFailed):
+				response.JSON(w, http.StatusConflict, err.Error())
+			case errors.Is(err, request.ErrRequestPatchPathNotFound):
+				response.JSON(w, http.StatusUnprocessableEntity, err.Error())
+			default:
+				response.JSON(w, http.StatusBadRequest, "invalid patch")
+			}
 			return
 		}
+		body, errs := decodeProductBody(patched)
+		if len(errs) > 0 {
+			responseValidationError(w, errs)
+			return
+		}
+		// - validate fields
+		if errs := body.Validate(); len(errs) > 0 {
+			responseValidationError(w, errs)
+			return
+		}
+		// - expiration, its format is validated above
+		exp, _ := time.Parse(time.DateOnly, body.Expiration)
 		// - update product
 		p.Name = body.Name
 		p.Quantity = body.Quantity
@@ -334,7 +654,7 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 		p.IdWarehouse = body.IdWarehouse
 		err = h.rpProd.Update(&p)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			responseProductWriteError(w, err)
 			return
 		}
 
@@ -350,6 +670,7 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 			Price:       p.Price,
 			IdWarehouse: p.IdWarehouse,
 		}
+		w.Header().Set("ETag", productETag(p.Version))
 		response.JSON(w, http.StatusOK, map[string]any{
 			"message": "success",
 			"data":    data,
@@ -357,7 +678,40 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 	}
 }
 
+// decodeProductBody decodes a product document, like a patched product or a row of a bulk import, into a product body.
+// Members that are not product fields and members of the wrong type are field errors.
+func decodeProductBody(doc []byte) (body RequestBodyProductCreate, errs FieldErrors) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&body)
+
+	var errType *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+	case errors.As(err, &errType):
+		switch errType.Type.Kind() {
+		case reflect.Int:
+			errs.add(errType.Field, "must be an integer")
+		case reflect.Float64:
+			errs.add(errType.Field, "must be a number")
+		case reflect.Bool:
+			errs.add(errType.Field, "must be a boolean")
+		default:
+			errs.add(errType.Field, "must be a string")
+		}
+	default:
+		// the decoder reports unknown fields as: json: unknown field "name"
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			errs.add(strings.Trim(field, `"`), "is not a product field")
+			return
+		}
+		errs.add("", err.Error())
+	}
+	return
+}
+
 // Delete deletes a product.
+// The If-Match header must match the ETag of the product.
 func (h *HandlerProduct) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
@@ -369,12 +723,29 @@ func (h *HandlerProduct) Delete() http.HandlerFunc {
 		}
 
 		// process
-		// - delete product by id
-		err = h.rpProd.Delete(id)
+		// - find product by id
+		p, err := h.rpProd.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				response.JSON(w, http.StatusNotFound, "product not found")
+			default:
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+		// - check version
+		if !checkIfMatch(w, r, p.Version) {
+			return
+		}
+		// - delete product by id at its version
+		err = h.rpProd.Delete(id, p.Version)
 		if err != nil {
 			switch {
 			case errors.Is(err, internal.ErrRepositoryProductNotFound):
 				response.JSON(w, http.StatusNotFound, "product not found")
+			case errors.Is(err, internal.ErrRepositoryProductVersionMismatch):
+				response.JSON(w, http.StatusPreconditionFailed, "product was modified, get it again")
 			default:
 				response.JSON(w, http.StatusInternalServerError, "internal server error")
 			}
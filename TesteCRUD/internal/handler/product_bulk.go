@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxBulkRows is the maximum number of products of a bulk import.
+	maxBulkRows = 1000
+	// maxBulkBytes is the maximum size of the body of a bulk import.
+	maxBulkBytes = 10 << 20
+)
+
+// productCSVHeader is the header of a CSV file of products.
+// The id is written on export and ignored on import, where the ids are assigned.
+var productCSVHeader = []string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "id_warehouse"}
+
+// BulkRowJSON is the result of a row of a bulk import in JSON format.
+type BulkRowJSON struct {
+	Row    int         `json:"row"`
+	Status string      `json:"status"`
+	Id     int         `json:"id,omitempty"`
+	Errors FieldErrors `json:"errors,omitempty"`
+	Error  any         `json:"error,omitempty"`
+}
+
+const (
+	// bulkRowCreated is the status of a row saved as a product.
+	bulkRowCreated = "created"
+	// bulkRowInvalid is the status of a row with invalid fields.
+	bulkRowInvalid = "invalid"
+	// bulkRowFailed is the status of a valid row the repository did not save.
+	bulkRowFailed = "failed"
+	// bulkRowNotCreated is the status of a valid row not saved because another row failed the import.
+	bulkRowNotCreated = "not_created"
+)
+
+// bulkRow is a decoded row of a bulk import.
+type bulkRow struct {
+	// body is the product of the row.
+	body RequestBodyProductCreate
+	// errs are the errors of the fields that could not be decoded.
+	errs FieldErrors
+}
+
+// errBulkTooManyRows is returned when a bulk import has more than maxBulkRows rows.
+var errBulkTooManyRows = fmt.Errorf("too many rows, at most %d", maxBulkRows)
+
+// decodeBulkJSON decodes a JSON array of products.
+func decodeBulkJSON(r io.Reader) (rows []bulkRow, err error) {
+	var raw []json.RawMessage
+	err = json.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return
+	}
+	if len(raw) > maxBulkRows {
+		return nil, errBulkTooManyRows
+	}
+
+	rows = make([]bulkRow, len(raw))
+	for ix, v := range raw {
+		rows[ix].body, rows[ix].errs = decodeProductBody(v)
+	}
+	return
+}
+
+// decodeBulkCSV decodes a CSV file of products, its columns are named by the header.
+func decodeBulkCSV(r io.Reader) (rows []bulkRow, err error) {
+	rd := csv.NewReader(r)
+	header, err := rd.Read()
+	if err != nil {
+		return
+	}
+
+	// columns
+	columns := make(map[string]int)
+	for ix, name := range header {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(productCSVHeader, name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = ix
+	}
+	for _, name := range productCSVHeader[1:] {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	// rows
+	for {
+		var record []string
+		record, err = rd.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == maxBulkRows {
+			return nil, errBulkTooManyRows
+		}
+
+		var row bulkRow
+		row.body.Name = record[columns["name"]]
+		row.body.CodeValue = record[columns["code_value"]]
+		row.body.Expiration = record[columns["expiration"]]
+		if row.body.Quantity, err = strconv.Atoi(record[columns["quantity"]]); err != nil {
+			row.errs.add("quantity", "must be an integer")
+		}
+		if row.body.IsPublished, err = strconv.ParseBool(record[columns["is_published"]]); err != nil {
+			row.errs.add("is_published", "must be a boolean")
+		}
+		if row.body.Price, err = strconv.ParseFloat(record[columns["price"]], 64); err != nil {
+			row.errs.add("price", "must be a number")
+		}
+		if row.body.IdWarehouse, err = strconv.Atoi(record[columns["id_warehouse"]]); err != nil {
+			row.errs.add("id_warehouse", "must be an integer")
+		}
+		rows = append(rows, row)
+	}
+}
+
+// decodeBulk decodes the body of a bulk import: a JSON array, a CSV file,
+// or a multipart form whose file field holds one of them, JSON when its name ends with .json.
+func decodeBulk(r *http.Request) (rows []bulkRow, err error) {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "application/json":
+		return decodeBulkJSON(r.Body)
+	case "text/csv":
+		return decodeBulkCSV(r.Body)
+	case "multipart/form-data":
+		f, fh, err := r.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if strings.HasSuffix(strings.ToLower(fh.Filename), ".json") {
+			return decodeBulkJSON(f)
+		}
+		return decodeBulkCSV(f)
+	default:
+		return nil, errUnsupportedMediaType
+	}
+}
+
+// errUnsupportedMediaType is returned when a bulk import is neither JSON, CSV nor a multipart form.
+var errUnsupportedMediaType = errors.New("content type must be application/json, text/csv or multipart/form-data")
+
+// Bulk creates many products at once.
+// Every row is validated and the valid ones are saved in a single write. In the default atomic mode
+// no product is created when any row fails, and in best_effort mode the rows that can be saved are.
+// The response reports the result of every row.
+func (h *HandlerProduct) Bulk() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: mode, atomic (default) or best_effort
+		var atomic bool
+		switch r.URL.Query().Get("mode") {
+		case "", "atomic":
+			atomic = true
+		case "best_effort":
+		default:
+			response.JSON(w, http.StatusBadRequest, "invalid mode, expected atomic or best_effort")
+			return
+		}
+		// - query parameter: force, creates already expired products
+		force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+		// - body
+		r.Body = http.MaxBytesReader(w, r.Body, maxBulkBytes)
+		rows, err := decodeBulk(r)
+		if err != nil {
+			var errMaxBytes *http.MaxBytesError
+			switch {
+			case errors.Is(err, errUnsupportedMediaType):
+				response.JSON(w, http.StatusUnsupportedMediaType, err.Error())
+			case errors.Is(err, errBulkTooManyRows), errors.As(err, &errMaxBytes):
+				response.JSON(w, http.StatusRequestEntityTooLarge, err.Error())
+			default:
+				response.JSON(w, http.StatusBadRequest, "invalid body: "+err.Error())
+			}
+			return
+		}
+		if len(rows) == 0 {
+			response.JSON(w, http.StatusBadRequest, "invalid body: no products")
+			return
+		}
+
+		// process
+		// - validate rows
+		report := make([]BulkRowJSON, len(rows))
+		var ps []internal.Product
+		var psRows []int
+		for ix, row := range rows {
+			report[ix].Row = ix + 1
+
+			errs := row.errs
+			if len(errs) == 0 {
+				errs = row.body.Validate()
+			}
+			var exp time.Time
+			if len(errs) == 0 {
+				// - expiration, its format is validated above
+				exp, _ = time.Parse(time.DateOnly, row.body.Expiration)
+				if exp.Before(internal.Today()) && !force {
+					errs.add("expiration", "is in the past, set force=true to create it anyway")
+				}
+			}
+			if len(errs) > 0 {
+				report[ix].Status = bulkRowInvalid
+				report[ix].Errors = errs
+				continue
+			}
+
+			ps = append(ps, internal.Product{
+				ProductAttributes: internal.ProductAttributes{
+					Name:        row.body.Name,
+					Quantity:    row.body.Quantity,
+					CodeValue:   row.body.CodeValue,
+					IsPublished: row.body.IsPublished,
+					Expiration:  exp,
+					Price:       row.body.Price,
+				},
+				IdWarehouse: row.body.IdWarehouse,
+			})
+			psRows = append(psRows, ix)
+		}
+		// - save products, none when an atomic import has invalid rows
+		var errs []error
+		if !atomic || len(ps) == len(rows) {
+			errs, err = h.rpProd.SaveAll(ps, atomic)
+			if err != nil {
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+		}
+		var created int
+		for i, p := range ps {
+			ix := psRows[i]
+			switch {
+			case errs != nil && errs[i] != nil:
+				report[ix].Status = bulkRowFailed
+				_, report[ix].Error = productWriteError(errs[i])
+			case p.Id == 0:
+				report[ix].Status = bulkRowNotCreated
+			default:
+				report[ix].Status = bulkRowCreated
+				report[ix].Id = p.Id
+				created++
+			}
+		}
+
+		// response
+		code, message := http.StatusCreated, "success"
+		switch {
+		case created == len(rows):
+		case created > 0:
+			code, message = http.StatusOK, "some products were not created"
+		default:
+			code, message = http.StatusUnprocessableEntity, "no products were created"
+		}
+		response.JSON(w, code, map[string]any{
+			"message": message,
+			"data": map[string]any{
+				"created": created,
+				"failed":  len(rows) - created,
+				"rows":    report,
+			},
+		})
+	}
+}
+
+// productExportFormats are the content types of the export formats.
+var productExportFormats = map[string]string{
+	"csv":    "text/csv",
+	"json":   "application/json",
+	"ndjson": "application/x-ndjson",
+}
+
+// Export streams all the products, sorted by id, as a CSV file, a JSON array or newline delimited JSON.
+func (h *HandlerProduct) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: format, json (default), csv or ndjson
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		contentType, ok := productExportFormats[format]
+		if !ok {
+			response.JSON(w, http.StatusBadRequest, "invalid format, expected csv, json or ndjson")
+			return
+		}
+
+		// process
+		// - the response starts with the first product, so a failed read can still be reported
+		enc := json.NewEncoder(w)
+		cw := csv.NewWriter(w)
+		var started bool
+		start := func() {
+			started = true
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Disposition", `attachment; filename="products.`+format+`"`)
+			w.WriteHeader(http.StatusOK)
+			switch format {
+			case "csv":
+				cw.Write(productCSVHeader)
+			case "json":
+				io.WriteString(w, "[")
+			}
+		}
+		var n int
+		err := h.rpProd.ForEach(func(p internal.Product) error {
+			if !started {
+				start()
+			}
+			n++
+
+			switch format {
+			case "csv":
+				cw.Write([]string{
+					strconv.Itoa(p.Id),
+					p.Name,
+					strconv.Itoa(p.Quantity),
+					p.CodeValue,
+					strconv.FormatBool(p.IsPublished),
+					p.Expiration.Format(time.DateOnly),
+					strconv.FormatFloat(p.Price, 'f', -1, 64),
+					strconv.Itoa(p.IdWarehouse),
+				})
+				return cw.Error()
+			case "json":
+				if n > 1 {
+					io.WriteString(w, ",")
+				}
+			}
+			return enc.Encode(ProductJSON{
+				Id:          p.Id,
+				Name:        p.Name,
+				Quantity:    p.Quantity,
+				CodeValue:   p.CodeValue,
+				IsPublished: p.IsPublished,
+				Expiration:  p.Expiration.Format(time.DateOnly),
+				Price:       p.Price,
+				IdWarehouse: p.IdWarehouse,
+			})
+		})
+		if err != nil {
+			if !started {
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			// the status is already sent, abort the connection so the file is not taken as complete
+			panic(http.ErrAbortHandler)
+		}
+
+		// response
+		// - no products
+		if !started {
+			start()
+		}
+		switch format {
+		case "csv":
+			cw.Flush()
+		case "json":
+			io.WriteString(w, "]\n")
+		}
+	}
+}
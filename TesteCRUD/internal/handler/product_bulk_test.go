@@ -0,0 +1,351 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bulkResponse is the body of a bulk import response.
+type bulkResponse struct {
+	Message string `json:"message"`
+	Data    struct {
+		Created int                   `json:"created"`
+		Failed  int                   `json:"failed"`
+		Rows    []handler.BulkRowJSON `json:"rows"`
+	} `json:"data"`
+}
+
+// requestBulk runs a bulk import of body with the content type ct and the query parameters query.
+func requestBulk(t *testing.T, hd *handler.HandlerProduct, query, ct, body string) (res *httptest.ResponseRecorder, out bulkResponse) {
+	req := httptest.NewRequest(http.MethodPost, "/products/bulk"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", ct)
+	res = httptest.NewRecorder()
+
+	hd.Bulk()(res, req)
+
+	if res.Code != http.StatusBadRequest && res.Code != http.StatusUnsupportedMediaType && res.Code != http.StatusRequestEntityTooLarge {
+		err := json.Unmarshal(res.Body.Bytes(), &out)
+		require.NoError(t, err)
+	}
+	return
+}
+
+// Tests for HandlerProduct.Bulk with CSV bodies
+func TestHandlerProduct_Bulk_CSV(t *testing.T) {
+	t.Run("success - columns in any order, id ignored", func(t *testing.T) {
+		// arrange
+		hd, st := newHandlerProductMemory(t)
+		body := "price,name,quantity,code_value,is_published,expiration,id_warehouse,id\n" +
+			"1.5,Corn Shoots,10,code-1,true,2099-01-01,1,99\n" +
+			"2,\"Pears, green\",0,code-2,false,2099-01-02,1,\n"
+
+		// act
+		res, out := requestBulk(t, hd, "", "text/csv", body)
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.Equal(t, 2, out.Data.Created)
+		require.Equal(t, []handler.BulkRowJSON{{Row: 1, Status: "created", Id: 1}, {Row: 2, Status: "created", Id: 2}}, out.Data.Rows)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, "Pears, green", ps[2].Name)
+		require.Equal(t, 1.5, ps[1].Price)
+	})
+
+	t.Run("error - unknown column", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		body := "name,quantity,code_value,is_published,expiration,price,id_warehouse,color\n"
+
+		// act
+		res, _ := requestBulk(t, hd, "", "text/csv", body)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.JSONEq(t, `"invalid body: unknown column \"color\""`, res.Body.String())
+	})
+
+	t.Run("error - missing column", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		body := "name,quantity,code_value,is_published,expiration,id_warehouse\n"
+
+		// act
+		res, _ := requestBulk(t, hd, "", "text/csv", body)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.JSONEq(t, `"invalid body: missing column \"price\""`, res.Body.String())
+	})
+
+	t.Run("error - cells of the wrong type", func(t *testing.T) {
+		// arrange
+		hd, st := newHandlerProductMemory(t)
+		body := "name,quantity,code_value,is_published,expiration,price,id_warehouse\n" +
+			"Corn Shoots,10,code-1,true,2099-01-01,1.5,1\n" +
+			"Pears,ten,code-2,yes,2099-01-01,cheap,first\n"
+
+		// act
+		res, out := requestBulk(t, hd, "", "text/csv", body)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.Equal(t, "no products were created", out.Message)
+		require.Equal(t, []handler.BulkRowJSON{
+			{Row: 1, Status: "not_created"},
+			{Row: 2, Status: "invalid", Errors: handler.FieldErrors{
+				{Field: "quantity", Error: "must be an integer"},
+				{Field: "is_published", Error: "must be a boolean"},
+				{Field: "price", Error: "must be a number"},
+				{Field: "id_warehouse", Error: "must be an integer"},
+			}},
+		}, out.Data.Rows)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
+
+	t.Run("error - too many rows", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		body := "name,quantity,code_value,is_published,expiration,price,id_warehouse\n" +
+			strings.Repeat("Corn Shoots,10,code-1,true,2099-01-01,1.5,1\n", 1001)
+
+		// act
+		res, _ := requestBulk(t, hd, "", "text/csv", body)
+
+		// assert
+		require.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+		require.JSONEq(t, `"too many rows, at most 1000"`, res.Body.String())
+	})
+}
+
+// Tests for HandlerProduct.Bulk with JSON bodies
+func TestHandlerProduct_Bulk_JSON(t *testing.T) {
+	// - the second product is in a warehouse that does not exist
+	body := `[
+		{"name":"Corn Shoots","quantity":10,"code_value":"code-1","is_published":true,"expiration":"2099-01-01","price":1.5,"id_warehouse":1},
+		{"name":"Pears","quantity":1,"code_value":"code-2","is_published":true,"expiration":"2099-01-01","price":2,"id_warehouse":2}
+	]`
+
+	t.Run("success - best effort saves the rows that can be saved", func(t *testing.T) {
+		// arrange
+		hd, st := newHandlerProductMemory(t)
+
+		// act
+		res, out := requestBulk(t, hd, "?mode=best_effort", "application/json", body)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "some products were not created", out.Message)
+		require.Equal(t, 1, out.Data.Created)
+		require.Equal(t, 1, out.Data.Failed)
+		require.Equal(t, []handler.BulkRowJSON{{Row: 1, Status: "created", Id: 1}, {Row: 2, Status: "failed", Error: "warehouse not found"}}, out.Data.Rows)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+	})
+
+	t.Run("error - atomic saves nothing when a row fails", func(t *testing.T) {
+		// arrange
+		hd, st := newHandlerProductMemory(t)
+
+		// act
+		res, out := requestBulk(t, hd, "", "application/json", body)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.Equal(t, 0, out.Data.Created)
+		require.Equal(t, 2, out.Data.Failed)
+		require.Equal(t, []handler.BulkRowJSON{{Row: 1, Status: "not_created"}, {Row: 2, Status: "failed", Error: "warehouse not found"}}, out.Data.Rows)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
+
+	t.Run("error - unknown members and members of the wrong type", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		body := `[
+			{"name":"Corn Shoots","quantity":10,"code_value":"code-1","is_published":true,"expiration":"2099-01-01","price":1.5,"id_warehouse":1,"color":"green"},
+			{"name":"Pears","quantity":"ten","code_value":"code-2","is_published":true,"expiration":"2099-01-01","price":2,"id_warehouse":1}
+		]`
+
+		// act
+		res, out := requestBulk(t, hd, "?mode=best_effort", "application/json", body)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.Equal(t, []handler.BulkRowJSON{
+			{Row: 1, Status: "invalid", Errors: handler.FieldErrors{{Field: "color", Error: "is not a product field"}}},
+			{Row: 2, Status: "invalid", Errors: handler.FieldErrors{{Field: "quantity", Error: "must be an integer"}}},
+		}, out.Data.Rows)
+	})
+
+	t.Run("error - past expiration without force", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		yesterday := internal.Today().AddDate(0, 0, -1).Format(time.DateOnly)
+		body := `[{"name":"Corn Shoots","quantity":10,"code_value":"code-1","is_published":true,"expiration":"` + yesterday + `","price":1.5,"id_warehouse":1}]`
+
+		// act
+		res, out := requestBulk(t, hd, "", "application/json", body)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.Equal(t, handler.FieldErrors{{Field: "expiration", Error: "is in the past, set force=true to create it anyway"}}, out.Data.Rows[0].Errors)
+	})
+
+	t.Run("error - too many rows", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		body := "[" + strings.Repeat("{},", 1000) + "{}]"
+
+		// act
+		res, _ := requestBulk(t, hd, "", "application/json", body)
+
+		// assert
+		require.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+		require.JSONEq(t, `"too many rows, at most 1000"`, res.Body.String())
+	})
+
+	t.Run("error - body too large", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		body := "[" + strings.Repeat(" ", 10<<20) + "]"
+
+		// act
+		res, _ := requestBulk(t, hd, "", "application/json", body)
+
+		// assert
+		require.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+		require.JSONEq(t, `"http: request body too large"`, res.Body.String())
+	})
+
+	t.Run("error - no products", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+
+		// act
+		res, _ := requestBulk(t, hd, "", "application/json", "[]")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.JSONEq(t, `"invalid body: no products"`, res.Body.String())
+	})
+}
+
+// Tests for HandlerProduct.Bulk with other bodies
+func TestHandlerProduct_Bulk(t *testing.T) {
+	t.Run("success - multipart form with a json file", func(t *testing.T) {
+		// arrange
+		hd, st := newHandlerProductMemory(t)
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, err := mw.CreateFormFile("file", "products.JSON")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(`[{"name":"Corn Shoots","quantity":10,"code_value":"code-1","is_published":true,"expiration":"2099-01-01","price":1.5,"id_warehouse":1}]`))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		// act
+		res, out := requestBulk(t, hd, "", mw.FormDataContentType(), buf.String())
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.Equal(t, 1, out.Data.Created)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+	})
+
+	t.Run("error - unsupported media type", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+
+		// act
+		res, _ := requestBulk(t, hd, "", "text/plain", "Corn Shoots")
+
+		// assert
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		require.JSONEq(t, `"content type must be application/json, text/csv or multipart/form-data"`, res.Body.String())
+	})
+
+	t.Run("error - invalid mode", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+
+		// act
+		res, _ := requestBulk(t, hd, "?mode=all", "application/json", "[]")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.JSONEq(t, `"invalid mode, expected atomic or best_effort"`, res.Body.String())
+	})
+}
+
+// Tests for HandlerProduct.Export
+func TestHandlerProduct_Export(t *testing.T) {
+	p1 := internal.Product{Id: 1, ProductAttributes: internal.ProductAttributes{Name: "Corn Shoots", Quantity: 10, CodeValue: "code-1", IsPublished: true, Expiration: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), Price: 1.5}, IdWarehouse: 1, Version: 1}
+	p2 := internal.Product{Id: 2, ProductAttributes: internal.ProductAttributes{Name: "Pears, green", Quantity: 0, CodeValue: "code-2", Expiration: time.Date(2099, 1, 2, 0, 0, 0, 0, time.UTC), Price: 2}, IdWarehouse: 1, Version: 1}
+	json1 := `{"id":1,"name":"Corn Shoots","quantity":10,"code_value":"code-1","is_published":true,"expiration":"2099-01-01","price":1.5,"id_warehouse":1}`
+	json2 := `{"id":2,"name":"Pears, green","quantity":0,"code_value":"code-2","is_published":false,"expiration":"2099-01-02","price":2,"id_warehouse":1}`
+
+	cases := []struct {
+		name        string
+		format      string
+		products    []internal.Product
+		contentType string
+		body        string
+	}{
+		{name: "success - json by default", format: "", products: []internal.Product{p2, p1}, contentType: "application/json", body: "[" + json1 + "\n," + json2 + "\n]\n"},
+		{name: "success - json of one product", format: "json", products: []internal.Product{p1}, contentType: "application/json", body: "[" + json1 + "\n]\n"},
+		{name: "success - json of no products", format: "json", contentType: "application/json", body: "[]\n"},
+		{name: "success - ndjson", format: "ndjson", products: []internal.Product{p1, p2}, contentType: "application/x-ndjson", body: json1 + "\n" + json2 + "\n"},
+		{name: "success - csv", format: "csv", products: []internal.Product{p1, p2}, contentType: "text/csv", body: "id,name,quantity,code_value,is_published,expiration,price,id_warehouse\n1,Corn Shoots,10,code-1,true,2099-01-01,1.5,1\n2,\"Pears, green\",0,code-2,false,2099-01-02,2,1\n"},
+		{name: "success - csv of no products", format: "csv", contentType: "text/csv", body: "id,name,quantity,code_value,is_published,expiration,price,id_warehouse\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			hd, _ := newHandlerProductMemory(t, c.products...)
+			req := httptest.NewRequest(http.MethodGet, "/products/export?format="+c.format, nil)
+			res := httptest.NewRecorder()
+
+			// act
+			hd.Export()(res, req)
+
+			// assert
+			require.Equal(t, http.StatusOK, res.Code)
+			require.Equal(t, c.contentType, res.Header().Get("Content-Type"))
+			require.Equal(t, c.body, res.Body.String())
+			if c.contentType == "application/json" {
+				require.True(t, json.Valid(res.Body.Bytes()))
+			}
+		})
+	}
+
+	t.Run("error - invalid format", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t)
+		req := httptest.NewRequest(http.MethodGet, "/products/export?format=xml", nil)
+		res := httptest.NewRecorder()
+
+		// act
+		hd.Export()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.JSONEq(t, `"invalid format, expected csv, json or ndjson"`, res.Body.String())
+	})
+}
@@ -0,0 +1,408 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/store"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// warehouseStub is a repository with a single warehouse, big enough for every product.
+type warehouseStub struct {
+	internal.RepositoryWarehouse
+}
+
+func (warehouseStub) FindById(id int) (internal.Warehouse, error) {
+	if id != 1 {
+		return internal.Warehouse{}, internal.ErrRepositoryWarehouseNotFound
+	}
+	return internal.Warehouse{Id: 1, Name: "Main Warehouse", Capacity: 1_000_000}, nil
+}
+
+// Tests for HandlerProduct.Create
+func TestHandlerProduct_Create(t *testing.T) {
+	t.Run("success - parallel creates get unique ids", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		err := os.WriteFile(path, []byte(`[{"id":1,"name":"Corn Shoots","quantity":244,"code_value":"0009-1111","is_published":false,"expiration":"2099-01-08","price":23.27,"id_warehouse":1}]`), 0644)
+		require.NoError(t, err)
+		st := store.NewStoreProductJSON(path)
+		rp := repository.NewRepositoryProductStore(st, warehouseStub{})
+		hd := handler.NewHandlerProduct(rp)
+
+		// act
+		const n = 50
+		codes := make([]int, n)
+		ids := make([]int, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				body := fmt.Sprintf(`{"name":"Product %d","quantity":1,"code_value":"code-%d","is_published":true,"expiration":"2099-01-01","price":1.5,"id_warehouse":1}`, i, i)
+				req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				res := httptest.NewRecorder()
+				hd.Create()(res, req)
+
+				codes[i] = res.Code
+				var out struct {
+					Data handler.ProductJSON `json:"data"`
+				}
+				json.NewDecoder(res.Body).Decode(&out)
+				ids[i] = out.Data.Id
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		seen := make(map[int]bool)
+		for i := 0; i < n; i++ {
+			require.Equal(t, http.StatusCreated, codes[i])
+			require.NotEqual(t, 1, ids[i])
+			require.False(t, seen[ids[i]], "id %d assigned twice", ids[i])
+			seen[ids[i]] = true
+		}
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Len(t, ps, n+1)
+		seq, err := os.ReadFile(path + ".seq")
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%d\n", n+1), string(seq))
+	})
+}
+
+// newHandlerProductMemory returns a handler on a product store in memory with ps, and the store.
+func newHandlerProductMemory(t *testing.T, ps ...internal.Product) (hd *handler.HandlerProduct, st *store.StoreMemory[internal.Product]) {
+	st = store.NewStoreMemory[internal.Product]()
+	m := make(map[int]internal.Product)
+	for _, p := range ps {
+		m[p.Id] = p
+	}
+	err := st.WriteAll(m)
+	require.NoError(t, err)
+	hd = handler.NewHandlerProduct(repository.NewRepositoryProductStore(st, warehouseStub{}))
+	return
+}
+
+// withURLParam returns req with the path parameter key set to value, as routed by chi.
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// productExpiring returns a published product of warehouse 1 expiring days from today.
+func productExpiring(id int, days int) internal.Product {
+	return internal.Product{
+		Id: id,
+		ProductAttributes: internal.ProductAttributes{
+			Name:        fmt.Sprintf("Product %d", id),
+			Quantity:    1,
+			CodeValue:   fmt.Sprintf("code-%d", id),
+			IsPublished: true,
+			Expiration:  internal.Today().AddDate(0, 0, days),
+			Price:       1.5,
+		},
+		IdWarehouse: 1,
+		Version:     1,
+	}
+}
+
+// responseProductIds returns the ids of the products of a page response.
+func responseProductIds(t *testing.T, res *httptest.ResponseRecorder) (ids []int) {
+	var out struct {
+		Data []handler.ProductJSON `json:"data"`
+	}
+	err := json.NewDecoder(res.Body).Decode(&out)
+	require.NoError(t, err)
+	ids = []int{}
+	for _, p := range out.Data {
+		ids = append(ids, p.Id)
+	}
+	return
+}
+
+// Tests for HandlerProduct.GetExpiring
+func TestHandlerProduct_GetExpiring(t *testing.T) {
+	// - products expiring yesterday, today, in a week and in 8 days
+	ps := []internal.Product{productExpiring(1, 8), productExpiring(2, -1), productExpiring(3, 7), productExpiring(4, 0)}
+
+	cases := []struct {
+		name   string
+		within string
+		ids    []int
+	}{
+		{name: "success - within days", within: "7", ids: []int{4, 3}},
+		{name: "success - within days with unit", within: "7d", ids: []int{4, 3}},
+		{name: "success - within weeks", within: "1w", ids: []int{4, 3}},
+		{name: "success - within zero days is today", within: "0d", ids: []int{4}},
+		{name: "success - default within 30 days", within: "", ids: []int{4, 3, 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			hd, _ := newHandlerProductMemory(t, ps...)
+			req := httptest.NewRequest(http.MethodGet, "/products/expiring?within="+c.within, nil)
+			res := httptest.NewRecorder()
+
+			// act
+			hd.GetExpiring()(res, req)
+
+			// assert
+			require.Equal(t, http.StatusOK, res.Code)
+			require.Equal(t, c.ids, responseProductIds(t, res))
+		})
+	}
+
+	for _, within := range []string{"x", "-1d", "1m", "w"} {
+		t.Run("error - invalid within "+within, func(t *testing.T) {
+			// arrange
+			hd, _ := newHandlerProductMemory(t, ps...)
+			req := httptest.NewRequest(http.MethodGet, "/products/expiring?within="+within, nil)
+			res := httptest.NewRecorder()
+
+			// act
+			hd.GetExpiring()(res, req)
+
+			// assert
+			require.Equal(t, http.StatusBadRequest, res.Code)
+			require.JSONEq(t, `"invalid within, must be a number of days (30d) or weeks (4w)"`, res.Body.String())
+		})
+	}
+}
+
+// Tests for HandlerProduct.GetExpired
+func TestHandlerProduct_GetExpired(t *testing.T) {
+	t.Run("success - expired before today", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t, productExpiring(1, -1), productExpiring(2, 0), productExpiring(3, -30), productExpiring(4, 1))
+		req := httptest.NewRequest(http.MethodGet, "/products/expired", nil)
+		res := httptest.NewRecorder()
+
+		// act
+		hd.GetExpired()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, []int{3, 1}, responseProductIds(t, res))
+	})
+}
+
+// Tests for the expiration check of HandlerProduct.Create and HandlerProduct.UpdateOrCreate
+func TestHandlerProduct_CreateExpired(t *testing.T) {
+	yesterday := internal.Today().AddDate(0, 0, -1).Format(time.DateOnly)
+	body := `{"name":"Corn Shoots","quantity":1,"code_value":"0009-1111","is_published":true,"expiration":"` + yesterday + `","price":1.5,"id_warehouse":1}`
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		code   int
+	}{
+		{name: "error - create with a past expiration", method: http.MethodPost, target: "/products", code: http.StatusUnprocessableEntity},
+		{name: "success - create with a past expiration and force", method: http.MethodPost, target: "/products?force=true", code: http.StatusCreated},
+		{name: "error - put creating with a past expiration", method: http.MethodPut, target: "/products/5", code: http.StatusUnprocessableEntity},
+		{name: "success - put creating with a past expiration and force", method: http.MethodPut, target: "/products/5?force=true", code: http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			hd, st := newHandlerProductMemory(t)
+			req := httptest.NewRequest(c.method, c.target, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = withURLParam(req, "id", "5")
+			res := httptest.NewRecorder()
+
+			// act
+			if c.method == http.MethodPost {
+				hd.Create()(res, req)
+			} else {
+				hd.UpdateOrCreate()(res, req)
+			}
+
+			// assert
+			require.Equal(t, c.code, res.Code)
+			ps, err := st.ReadAll()
+			require.NoError(t, err)
+			if c.code == http.StatusUnprocessableEntity {
+				require.JSONEq(t, `"expiration is in the past, set force=true to create it anyway"`, res.Body.String())
+				require.Empty(t, ps)
+				return
+			}
+			require.Len(t, ps, 1)
+		})
+	}
+
+	t.Run("success - put replacing with a past expiration", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandlerProductMemory(t, productExpiring(5, 1))
+		req := httptest.NewRequest(http.MethodPut, "/products/5", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
+		req = withURLParam(req, "id", "5")
+		res := httptest.NewRecorder()
+
+		// act
+		hd.UpdateOrCreate()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+	})
+}
+
+// Tests for HandlerProduct.Update
+func TestHandlerProduct_Update(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+		response    string
+	}{
+		{
+			name:        "success - merge patch",
+			contentType: "application/merge-patch+json",
+			body:        `{"price":2.5,"is_published":false}`,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "success - json patch",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"test","path":"/price","value":1.5},{"op":"replace","path":"/price","value":2.5}]`,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "error - json content type",
+			contentType: "application/json",
+			body:        `{"price":2.5}`,
+			code:        http.StatusUnsupportedMediaType,
+			response:    `"content type must be application/merge-patch+json or application/json-patch+json"`,
+		},
+		{
+			name:        "error - failed test operation",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"test","path":"/price","value":9},{"op":"replace","path":"/price","value":2.5}]`,
+			code:        http.StatusConflict,
+			response:    `"operation 0: request patch test failed: /price"`,
+		},
+		{
+			name:        "error - missing path",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"remove","path":"/color"}]`,
+			code:        http.StatusUnprocessableEntity,
+			response:    `"operation 0: request patch path not found: color"`,
+		},
+		{
+			name:        "error - merge patch null removes a required field",
+			contentType: "application/merge-patch+json",
+			body:        `{"name":null,"price":null}`,
+			code:        http.StatusUnprocessableEntity,
+			response:    `{"message":"invalid fields","errors":[{"field":"name","error":"is required"},{"field":"price","error":"must be > 0"}]}`,
+		},
+		{
+			name:        "error - unknown member",
+			contentType: "application/merge-patch+json",
+			body:        `{"color":"green"}`,
+			code:        http.StatusUnprocessableEntity,
+			response:    `{"message":"invalid fields","errors":[{"field":"color","error":"is not a product field"}]}`,
+		},
+		{
+			name:        "error - json patch adding an unknown member",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"add","path":"/color","value":"green"}]`,
+			code:        http.StatusUnprocessableEntity,
+			response:    `{"message":"invalid fields","errors":[{"field":"color","error":"is not a product field"}]}`,
+		},
+		{
+			name:        "error - member of the wrong type",
+			contentType: "application/merge-patch+json",
+			body:        `{"quantity":"ten"}`,
+			code:        http.StatusUnprocessableEntity,
+			response:    `{"message":"invalid fields","errors":[{"field":"quantity","error":"must be an integer"}]}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			p := productExpiring(1, 10)
+			hd, st := newHandlerProductMemory(t, p)
+			req := httptest.NewRequest(http.MethodPatch, "/products/1", strings.NewReader(c.body))
+			req.Header.Set("Content-Type", c.contentType)
+			req.Header.Set("If-Match", `"1"`)
+			req = withURLParam(req, "id", "1")
+			res := httptest.NewRecorder()
+
+			// act
+			hd.Update()(res, req)
+
+			// assert
+			require.Equal(t, c.code, res.Code)
+			ps, err := st.ReadAll()
+			require.NoError(t, err)
+			if c.code == http.StatusOK {
+				require.Equal(t, 2.5, ps[1].Price)
+				require.Equal(t, 2, ps[1].Version)
+				return
+			}
+			require.JSONEq(t, c.response, res.Body.String())
+			require.Equal(t, p, ps[1])
+			if c.code == http.StatusUnsupportedMediaType {
+				require.Equal(t, "application/merge-patch+json, application/json-patch+json", res.Header().Get("Accept-Patch"))
+			}
+		})
+	}
+}
+
+// Tests for the warehouse of the product writes, checked by the repository
+func TestHandlerProduct_WarehouseNotFound(t *testing.T) {
+	body := `{"name":"Corn Shoots","quantity":1,"code_value":"code-1","is_published":true,"expiration":"2099-01-01","price":1.5,"id_warehouse":2}`
+
+	cases := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		handler     func(hd *handler.HandlerProduct) http.HandlerFunc
+	}{
+		{name: "error - create", method: http.MethodPost, contentType: "application/json", body: body, handler: (*handler.HandlerProduct).Create},
+		{name: "error - update or create", method: http.MethodPut, contentType: "application/json", body: body, handler: (*handler.HandlerProduct).UpdateOrCreate},
+		{name: "error - update", method: http.MethodPatch, contentType: "application/merge-patch+json", body: `{"id_warehouse":2}`, handler: (*handler.HandlerProduct).Update},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			p := productExpiring(1, 10)
+			hd, st := newHandlerProductMemory(t, p)
+			req := httptest.NewRequest(c.method, "/products/1", strings.NewReader(c.body))
+			req.Header.Set("Content-Type", c.contentType)
+			req.Header.Set("If-Match", `"1"`)
+			req = withURLParam(req, "id", "1")
+			res := httptest.NewRecorder()
+
+			// act
+			c.handler(hd)(res, req)
+
+			// assert
+			require.Equal(t, http.StatusConflict, res.Code)
+			require.JSONEq(t, `"warehouse not found"`, res.Body.String())
+			ps, err := st.ReadAll()
+			require.NoError(t, err)
+			require.Equal(t, map[int]internal.Product{1: p}, ps)
+		})
+	}
+}
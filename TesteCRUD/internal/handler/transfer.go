@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewHandlerTransfer creates a new handler for transfers.
+func NewHandlerTransfer(rpT internal.RepositoryTransfer, rpW internal.RepositoryWarehouse) (h *HandlerTransfer) {
+	h = &HandlerTransfer{
+		rpTransfer: rpT,
+		rpWare:     rpW,
+	}
+	return
+}
+
+// HandlerTransfer is a handler for transfers of stock between warehouses.
+type HandlerTransfer struct {
+	// rpTransfer is the repository for transfers.
+	rpTransfer internal.RepositoryTransfer
+	// rpWare is the repository for warehouses.
+	rpWare internal.RepositoryWarehouse
+}
+
+// TransferJSON is a transfer in JSON format.
+type TransferJSON struct {
+	Id            int    `json:"id"`
+	CodeValue     string `json:"code_value"`
+	Quantity      int    `json:"quantity"`
+	FromWarehouse int    `json:"from_warehouse"`
+	ToWarehouse   int    `json:"to_warehouse"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// RequestBodyTransferCreate is a request body for creating a transfer.
+type RequestBodyTransferCreate struct {
+	CodeValue   string `json:"code_value"`
+	Quantity    int    `json:"quantity"`
+	ToWarehouse int    `json:"to_warehouse"`
+}
+
+// Create moves stock of a product from the warehouse of the path to another warehouse.
+func (h *HandlerTransfer) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var body RequestBodyTransferCreate
+		err = request.JSON(r, &body)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		if body.CodeValue == "" || body.Quantity <= 0 {
+			response.JSON(w, http.StatusBadRequest, "code_value is required and quantity must be greater than 0")
+			return
+		}
+
+		// process
+		// - check source warehouse
+		_, err = h.rpWare.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryWarehouseNotFound):
+				response.JSON(w, http.StatusNotFound, "warehouse not found")
+			default:
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+		// - move stock
+		t := internal.Transfer{
+			CodeValue:     body.CodeValue,
+			Quantity:      body.Quantity,
+			FromWarehouse: id,
+			ToWarehouse:   body.ToWarehouse,
+		}
+		err = h.rpTransfer.Save(&t)
+		if err != nil {
+			var errCapacity *internal.WarehouseCapacityError
+			switch {
+			case errors.Is(err, internal.ErrRepositoryTransferProductNotFound):
+				response.JSON(w, http.StatusNotFound, "product not found in warehouse")
+			case errors.Is(err, internal.ErrRepositoryTransferQuantityInvalid):
+				response.JSON(w, http.StatusConflict, "quantity is above the stock of the product")
+			case errors.Is(err, internal.ErrRepositoryTransferDestinationInvalid):
+				response.JSON(w, http.StatusConflict, "invalid to_warehouse")
+			case errors.As(err, &errCapacity):
+				response.JSON(w, http.StatusConflict, map[string]any{
+					"message":            "warehouse capacity exceeded",
+					"id_warehouse":       errCapacity.IdWarehouse,
+					"capacity":           errCapacity.Capacity,
+					"remaining_capacity": errCapacity.Remaining(),
+				})
+			default:
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+
+		// response
+		// - serialize transfer to JSON
+		data := TransferJSON{
+			Id:            t.Id,
+			CodeValue:     t.CodeValue,
+			Quantity:      t.Quantity,
+			FromWarehouse: t.FromWarehouse,
+			ToWarehouse:   t.ToWarehouse,
+			CreatedAt:     t.CreatedAt.Format(time.DateTime),
+		}
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// GetByWarehouse gets the transfers from or to a warehouse, newest first.
+func (h *HandlerTransfer) GetByWarehouse() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		// - check warehouse
+		_, err = h.rpWare.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryWarehouseNotFound):
+				response.JSON(w, http.StatusNotFound, "warehouse not found")
+			default:
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+		// - find transfers
+		transfers, err := h.rpTransfer.FindByWarehouse(id)
+		if err != nil {
+			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		// response
+		// - serialize transfers to JSON
+		data := []TransferJSON{}
+		for _, t := range transfers {
+			data = append(data, TransferJSON{
+				Id:            t.Id,
+				CodeValue:     t.CodeValue,
+				Quantity:      t.Quantity,
+				FromWarehouse: t.FromWarehouse,
+				ToWarehouse:   t.ToWarehouse,
+				CreatedAt:     t.CreatedAt.Format(time.DateTime),
+			})
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
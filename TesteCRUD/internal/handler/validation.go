@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FieldErrorJSON is a validation error of a field of a request body in JSON format.
+type FieldErrorJSON struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// FieldErrors is the list of validation errors of a request body.
+type FieldErrors []FieldErrorJSON
+
+// add adds an error of a field.
+func (e *FieldErrors) add(field, msg string) {
+	*e = append(*e, FieldErrorJSON{Field: field, Error: msg})
+}
+
+// responseValidationError writes the response of a request body with invalid fields.
+func responseValidationError(w http.ResponseWriter, errs FieldErrors) {
+	response.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"message": "invalid fields",
+		"errors":  errs,
+	})
+}
+
+// Validate validates the fields of a product body.
+// It is shared by the create, update or create, and update handlers so their rules are the same.
+func (b RequestBodyProductCreate) Validate() (errs FieldErrors) {
+	if strings.TrimSpace(b.Name) == "" {
+		errs.add("name", "is required")
+	}
+	if b.Quantity < 0 {
+		errs.add("quantity", "must be >= 0")
+	}
+	if strings.TrimSpace(b.CodeValue) == "" {
+		errs.add("code_value", "is required")
+	}
+	if _, err := time.Parse(time.DateOnly, b.Expiration); err != nil {
+		errs.add("expiration", "must be a date in YYYY-MM-DD format")
+	}
+	if b.Price <= 0 {
+		errs.add("price", "must be > 0")
+	}
+	if b.IdWarehouse <= 0 {
+		errs.add("id_warehouse", "must be > 0")
+	}
+	return
+}
+
+// Validate validates the fields of a warehouse body.
+// It is shared by the create, update and patch handlers so their rules are the same.
+func (b RequestBodyWarehouseCreate) Validate() (errs FieldErrors) {
+	if strings.TrimSpace(b.Name) == "" {
+		errs.add("name", "is required")
+	}
+	if strings.TrimSpace(b.Address) == "" {
+		errs.add("address", "is required")
+	}
+	if strings.TrimSpace(b.Telephone) == "" {
+		errs.add("telephone", "is required")
+	}
+	if b.Capacity <= 0 {
+		errs.add("capacity", "must be > 0")
+	}
+	return
+}
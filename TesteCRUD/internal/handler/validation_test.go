@@ -0,0 +1,192 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/store"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for RequestBodyProductCreate.Validate
+func TestRequestBodyProductCreate_Validate(t *testing.T) {
+	valid := handler.RequestBodyProductCreate{Name: "Corn Shoots", Quantity: 0, CodeValue: "0009-1111", Expiration: "2099-01-08", Price: 23.27, IdWarehouse: 1}
+
+	cases := []struct {
+		name   string
+		change func(b *handler.RequestBodyProductCreate)
+		errs   handler.FieldErrors
+	}{
+		{name: "success - valid", change: func(b *handler.RequestBodyProductCreate) {}},
+		{name: "error - name empty", change: func(b *handler.RequestBodyProductCreate) { b.Name = "" }, errs: handler.FieldErrors{{Field: "name", Error: "is required"}}},
+		{name: "error - name blank", change: func(b *handler.RequestBodyProductCreate) { b.Name = "  " }, errs: handler.FieldErrors{{Field: "name", Error: "is required"}}},
+		{name: "error - quantity negative", change: func(b *handler.RequestBodyProductCreate) { b.Quantity = -1 }, errs: handler.FieldErrors{{Field: "quantity", Error: "must be >= 0"}}},
+		{name: "error - code value blank", change: func(b *handler.RequestBodyProductCreate) { b.CodeValue = " " }, errs: handler.FieldErrors{{Field: "code_value", Error: "is required"}}},
+		{name: "error - expiration empty", change: func(b *handler.RequestBodyProductCreate) { b.Expiration = "" }, errs: handler.FieldErrors{{Field: "expiration", Error: "must be a date in YYYY-MM-DD format"}}},
+		{name: "error - expiration other format", change: func(b *handler.RequestBodyProductCreate) { b.Expiration = "08/01/2099" }, errs: handler.FieldErrors{{Field: "expiration", Error: "must be a date in YYYY-MM-DD format"}}},
+		{name: "error - price zero", change: func(b *handler.RequestBodyProductCreate) { b.Price = 0 }, errs: handler.FieldErrors{{Field: "price", Error: "must be > 0"}}},
+		{name: "error - id warehouse zero", change: func(b *handler.RequestBodyProductCreate) { b.IdWarehouse = 0 }, errs: handler.FieldErrors{{Field: "id_warehouse", Error: "must be > 0"}}},
+		{name: "error - every field", change: func(b *handler.RequestBodyProductCreate) { *b = handler.RequestBodyProductCreate{Quantity: -1} }, errs: productFieldErrors},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			b := valid
+			c.change(&b)
+
+			// act
+			errs := b.Validate()
+
+			// assert
+			require.Equal(t, c.errs, errs)
+		})
+	}
+}
+
+// Tests for RequestBodyWarehouseCreate.Validate
+func TestRequestBodyWarehouseCreate_Validate(t *testing.T) {
+	valid := handler.RequestBodyWarehouseCreate{Name: "Main Warehouse", Address: "221 Baker Street", Telephone: "4555666", Capacity: 100}
+
+	cases := []struct {
+		name   string
+		change func(b *handler.RequestBodyWarehouseCreate)
+		errs   handler.FieldErrors
+	}{
+		{name: "success - valid", change: func(b *handler.RequestBodyWarehouseCreate) {}},
+		{name: "error - name blank", change: func(b *handler.RequestBodyWarehouseCreate) { b.Name = " " }, errs: handler.FieldErrors{{Field: "name", Error: "is required"}}},
+		{name: "error - address blank", change: func(b *handler.RequestBodyWarehouseCreate) { b.Address = "" }, errs: handler.FieldErrors{{Field: "address", Error: "is required"}}},
+		{name: "error - telephone blank", change: func(b *handler.RequestBodyWarehouseCreate) { b.Telephone = "" }, errs: handler.FieldErrors{{Field: "telephone", Error: "is required"}}},
+		{name: "error - capacity zero", change: func(b *handler.RequestBodyWarehouseCreate) { b.Capacity = 0 }, errs: handler.FieldErrors{{Field: "capacity", Error: "must be > 0"}}},
+		{name: "error - capacity negative", change: func(b *handler.RequestBodyWarehouseCreate) { b.Capacity = -1 }, errs: handler.FieldErrors{{Field: "capacity", Error: "must be > 0"}}},
+		{name: "error - every field", change: func(b *handler.RequestBodyWarehouseCreate) { *b = handler.RequestBodyWarehouseCreate{} }, errs: warehouseFieldErrors},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			b := valid
+			c.change(&b)
+
+			// act
+			errs := b.Validate()
+
+			// assert
+			require.Equal(t, c.errs, errs)
+		})
+	}
+}
+
+var (
+	// productFieldErrors are the errors of a product body with every field invalid.
+	productFieldErrors = handler.FieldErrors{
+		{Field: "name", Error: "is required"},
+		{Field: "quantity", Error: "must be >= 0"},
+		{Field: "code_value", Error: "is required"},
+		{Field: "expiration", Error: "must be a date in YYYY-MM-DD format"},
+		{Field: "price", Error: "must be > 0"},
+		{Field: "id_warehouse", Error: "must be > 0"},
+	}
+	// warehouseFieldErrors are the errors of a warehouse body with every field invalid.
+	warehouseFieldErrors = handler.FieldErrors{
+		{Field: "name", Error: "is required"},
+		{Field: "address", Error: "is required"},
+		{Field: "telephone", Error: "is required"},
+		{Field: "capacity", Error: "must be > 0"},
+	}
+)
+
+// responseFieldErrorsJSON returns the body of a 422 response with errs.
+func responseFieldErrorsJSON(errs handler.FieldErrors) string {
+	var b strings.Builder
+	b.WriteString(`{"message":"invalid fields","errors":[`)
+	for i, e := range errs {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(`{"field":"` + e.Field + `","error":"` + e.Error + `"}`)
+	}
+	b.WriteString("]}")
+	return b.String()
+}
+
+// Tests for the validation of the product handlers writing a product
+func TestHandlerProduct_Validation(t *testing.T) {
+	// - every field invalid
+	body := `{"name":" ","quantity":-1,"code_value":"","is_published":true,"expiration":"08/01/2099","price":0,"id_warehouse":0}`
+
+	cases := []struct {
+		name        string
+		method      string
+		contentType string
+		handler     func(hd *handler.HandlerProduct) http.HandlerFunc
+	}{
+		{name: "error - create", method: http.MethodPost, contentType: "application/json", handler: (*handler.HandlerProduct).Create},
+		{name: "error - update or create", method: http.MethodPut, contentType: "application/json", handler: (*handler.HandlerProduct).UpdateOrCreate},
+		{name: "error - update", method: http.MethodPatch, contentType: "application/merge-patch+json", handler: (*handler.HandlerProduct).Update},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			hd, st := newHandlerProductMemory(t, productExpiring(1, 10))
+			req := httptest.NewRequest(c.method, "/products/1", strings.NewReader(body))
+			req.Header.Set("Content-Type", c.contentType)
+			req.Header.Set("If-Match", `"1"`)
+			req = withURLParam(req, "id", "1")
+			res := httptest.NewRecorder()
+
+			// act
+			c.handler(hd)(res, req)
+
+			// assert
+			require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+			require.JSONEq(t, responseFieldErrorsJSON(productFieldErrors), res.Body.String())
+			ps, err := st.ReadAll()
+			require.NoError(t, err)
+			require.Equal(t, map[int]internal.Product{1: productExpiring(1, 10)}, ps)
+		})
+	}
+}
+
+// Tests for the validation of the warehouse handlers writing a warehouse
+func TestHandlerWarehouse_Validation(t *testing.T) {
+	// - every field invalid
+	body := `{"name":"","address":" ","telephone":"","capacity":0}`
+
+	cases := []struct {
+		name    string
+		method  string
+		handler func(hd *handler.HandlerWarehouse) http.HandlerFunc
+	}{
+		{name: "error - create", method: http.MethodPost, handler: (*handler.HandlerWarehouse).Create},
+		{name: "error - update", method: http.MethodPut, handler: (*handler.HandlerWarehouse).Update},
+		{name: "error - patch", method: http.MethodPatch, handler: (*handler.HandlerWarehouse).Patch},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			wh := internal.Warehouse{Id: 1, Name: "Main Warehouse", Address: "221 Baker Street", Telephone: "4555666", Capacity: 100}
+			st := store.NewStoreMemory[internal.Warehouse]()
+			err := st.WriteAll(map[int]internal.Warehouse{1: wh})
+			require.NoError(t, err)
+			hd := handler.NewHandlerWarehouse(repository.NewRepositoryWarehouseStore(st, store.NewStoreMemory[internal.Product]()))
+			req := httptest.NewRequest(c.method, "/warehouses/1", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = withURLParam(req, "id", "1")
+			res := httptest.NewRecorder()
+
+			// act
+			c.handler(hd)(res, req)
+
+			// assert
+			require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+			require.JSONEq(t, responseFieldErrorsJSON(warehouseFieldErrors), res.Body.String())
+			ws, err := st.ReadAll()
+			require.NoError(t, err)
+			require.Equal(t, map[int]internal.Warehouse{1: wh}, ws)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -118,6 +119,11 @@ func (h *HandlerWarehouse) Create() http.HandlerFunc {
 			response.JSON(w, http.StatusBadRequest, "invalid body")
 			return
 		}
+		// - validate fields
+		if errs := body.Validate(); len(errs) > 0 {
+			responseValidationError(w, errs)
+			return
+		}
 
 		// process
 		// - save warehouse
@@ -148,3 +154,306 @@ func (h *HandlerWarehouse) Create() http.HandlerFunc {
 		})
 	}
 }
+
+// responseWarehouseWriteError writes the response of a failed warehouse write.
+func responseWarehouseWriteError(w http.ResponseWriter, err error) {
+	var errCapacity *internal.WarehouseCapacityError
+	switch {
+	case errors.Is(err, internal.ErrRepositoryWarehouseNotFound):
+		response.JSON(w, http.StatusNotFound, "warehouse not found")
+	case errors.Is(err, internal.ErrRepositoryWarehouseCapacityTooLow):
+		response.JSON(w, http.StatusConflict, "capacity is lower than the quantity of products of the warehouse")
+	case errors.Is(err, internal.ErrRepositoryWarehouseInUse):
+		response.JSON(w, http.StatusConflict, "warehouse has products, set reassign_to to move them to another warehouse")
+	case errors.Is(err, internal.ErrRepositoryWarehouseReassignInvalid):
+		response.JSON(w, http.StatusConflict, "invalid reassign_to warehouse")
+	case errors.As(err, &errCapacity):
+		response.JSON(w, http.StatusConflict, map[string]any{
+			"message":            "warehouse capacity exceeded",
+			"id_warehouse":       errCapacity.IdWarehouse,
+			"capacity":           errCapacity.Capacity,
+			"remaining_capacity": errCapacity.Remaining(),
+		})
+	default:
+		response.JSON(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// Update replaces a warehouse.
+func (h *HandlerWarehouse) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - body
+		var body RequestBodyWarehouseCreate
+		err = request.JSON(r, &body)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		// - validate fields
+		if errs := body.Validate(); len(errs) > 0 {
+			responseValidationError(w, errs)
+			return
+		}
+
+		// process
+		// - update warehouse
+		wh := internal.Warehouse{
+			Id:        id,
+			Name:      body.Name,
+			Address:   body.Address,
+			Telephone: body.Telephone,
+			Capacity:  body.Capacity,
+		}
+		err = h.rp.Update(&wh)
+		if err != nil {
+			responseWarehouseWriteError(w, err)
+			return
+		}
+
+		// response
+		// - serialize warehouse to JSON
+		data := WarehouseJSON{
+			Id:        wh.Id,
+			Name:      wh.Name,
+			Address:   wh.Address,
+			Telephone: wh.Telephone,
+			Capacity:  wh.Capacity,
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// Patch updates the fields of a warehouse present in the body.
+func (h *HandlerWarehouse) Patch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		// - find warehouse by id
+		wh, err := h.rp.FindById(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryWarehouseNotFound):
+				response.JSON(w, http.StatusNotFound, "warehouse not found")
+			default:
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+		// - patch warehouse
+		body := RequestBodyWarehouseCreate{
+			Name:      wh.Name,
+			Address:   wh.Address,
+			Telephone: wh.Telephone,
+			Capacity:  wh.Capacity,
+		}
+		err = request.JSON(r, &body)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		// - validate fields
+		if errs := body.Validate(); len(errs) > 0 {
+			responseValidationError(w, errs)
+			return
+		}
+		// - update warehouse
+		wh.Name = body.Name
+		wh.Address = body.Address
+		wh.Telephone = body.Telephone
+		wh.Capacity = body.Capacity
+		err = h.rp.Update(&wh)
+		if err != nil {
+			responseWarehouseWriteError(w, err)
+			return
+		}
+
+		// response
+		// - serialize warehouse to JSON
+		data := WarehouseJSON{
+			Id:        wh.Id,
+			Name:      wh.Name,
+			Address:   wh.Address,
+			Telephone: wh.Telephone,
+			Capacity:  wh.Capacity,
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// Delete deletes a warehouse.
+// A warehouse with products is only deleted when the reassign_to query parameter names
+// the warehouse its products are moved to.
+func (h *HandlerWarehouse) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - query parameter: reassign_to
+		var reassignTo *int
+		if s := r.URL.Query().Get("reassign_to"); s != "" {
+			to, err := strconv.Atoi(s)
+			if err != nil {
+				response.JSON(w, http.StatusBadRequest, "invalid reassign_to")
+				return
+			}
+			reassignTo = &to
+		}
+
+		// process
+		// - delete warehouse by id
+		err = h.rp.Delete(id, reassignTo)
+		if err != nil {
+			responseWarehouseWriteError(w, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusNoContent, nil)
+	}
+}
+
+// WarehouseReportJSON is the stock report of a warehouse in JSON format.
+type WarehouseReportJSON struct {
+	WarehouseJSON
+	Products     int     `json:"products"`
+	Units        int     `json:"units"`
+	StockValue   float64 `json:"stock_value"`
+	Utilization  float64 `json:"utilization"`
+	Expired      int     `json:"expired"`
+	ExpiringSoon int     `json:"expiring_soon"`
+	Published    int     `json:"published"`
+	Unpublished  int     `json:"unpublished"`
+}
+
+// defaultReportWithin is the number of days ahead a product counts as expiring soon.
+const defaultReportWithin = 30
+
+// reportDates returns today and the last day a product counts as expiring soon,
+// from the query parameter within (days, default 30).
+func reportDates(r *http.Request) (today, soon time.Time, err error) {
+	within := defaultReportWithin
+	if s := r.URL.Query().Get("within"); s != "" {
+		within, err = strconv.Atoi(s)
+		if err != nil || within < 0 {
+			return today, soon, errors.New("invalid within")
+		}
+	}
+
+	today = internal.Today()
+	soon = today.AddDate(0, 0, within)
+	return today, soon, nil
+}
+
+// warehouseReportJSON serializes the stock report of a warehouse to JSON.
+func warehouseReportJSON(wr internal.WarehouseReport) WarehouseReportJSON {
+	return WarehouseReportJSON{
+		WarehouseJSON: WarehouseJSON{
+			Id:        wr.Id,
+			Name:      wr.Name,
+			Address:   wr.Address,
+			Telephone: wr.Telephone,
+			Capacity:  wr.Capacity,
+		},
+		Products:     wr.Products,
+		Units:        wr.Units,
+		StockValue:   wr.StockValue,
+		Utilization:  wr.Utilization,
+		Expired:      wr.Expired,
+		ExpiringSoon: wr.ExpiringSoon,
+		Published:    wr.Published,
+		Unpublished:  wr.Unpublished,
+	}
+}
+
+// GetReport gets the stock report of a warehouse.
+func (h *HandlerWarehouse) GetReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - path parameter: id
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - query parameter: within
+		today, soon, err := reportDates(r)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// process
+		// - report warehouse
+		wr, err := h.rp.Report(id, today, soon)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrRepositoryWarehouseNotFound):
+				response.JSON(w, http.StatusNotFound, "warehouse not found")
+			default:
+				response.JSON(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    warehouseReportJSON(wr),
+		})
+	}
+}
+
+// GetReportAll gets the stock report of every warehouse.
+func (h *HandlerWarehouse) GetReportAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: within
+		today, soon, err := reportDates(r)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// process
+		// - report warehouses
+		reports, err := h.rp.ReportAll(today, soon)
+		if err != nil {
+			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		// response
+		data := []WarehouseReportJSON{}
+		for _, wr := range reports {
+			data = append(data, warehouseReportJSON(wr))
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
@@ -26,4 +26,39 @@ type Product struct {
 	ProductAttributes
 	// IdWarehouse is the unique identifier of the Warehouse
 	IdWarehouse int
+	// Version is incremented on every write of the product, it starts at 1
+	Version int
+}
+
+// ProductSortFields are the fields a product search can be sorted by
+var ProductSortFields = []string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "id_warehouse"}
+
+// ProductQuery is a struct that contains the filters, sorting and pagination of a product search
+type ProductQuery struct {
+	// IsPublished filters by the published status, nil means any
+	IsPublished *bool
+	// IdWarehouse filters by the warehouse, nil means any
+	IdWarehouse *int
+	// PriceMin is the minimum price (inclusive), nil means unbounded
+	PriceMin *float64
+	// PriceMax is the maximum price (inclusive), nil means unbounded
+	PriceMax *float64
+	// ExpirationFrom is the first expiration date (inclusive), zero means unbounded
+	ExpirationFrom time.Time
+	// ExpirationTo is the last expiration date (inclusive), zero means unbounded
+	ExpirationTo time.Time
+	// Sort is one of ProductSortFields, ties are broken by id
+	Sort string
+	// Desc sorts in descending order
+	Desc bool
+	// Page is the page number, starting at 1
+	Page int
+	// PageSize is the number of products per page
+	PageSize int
+}
+
+// Today returns the current date at midnight UTC, the way expirations are stored
+func Today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 }
@@ -1,25 +1,81 @@
 package internal
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	// ErrRepositoryProductNotFound is returned when a product is not found.
 	ErrRepositoryProductNotFound = errors.New("repository: product not found")
+	// ErrRepositoryProductQueryInvalid is returned when the sort field or the pagination of a search is not supported.
+	ErrRepositoryProductQueryInvalid = errors.New("repository: invalid product query")
+	// ErrRepositoryProductWarehouseFull is returned when the quantity of a product does not fit in its warehouse.
+	ErrRepositoryProductWarehouseFull = errors.New("repository: warehouse capacity exceeded")
+	// ErrRepositoryProductDuplicated is returned when the warehouse already has another product with the code value.
+	ErrRepositoryProductDuplicated = errors.New("repository: product duplicated")
+	// ErrRepositoryProductVersionMismatch is returned when the stored product is not at the version of the write.
+	ErrRepositoryProductVersionMismatch = errors.New("repository: product version mismatch")
 )
 
+// WarehouseCapacityError is returned when the quantity of a product, or of the products moved into
+// a warehouse, does not fit in it. It wraps ErrRepositoryProductWarehouseFull.
+type WarehouseCapacityError struct {
+	// IdWarehouse is the unique identifier of the warehouse
+	IdWarehouse int
+	// Capacity is the capacity of the warehouse
+	Capacity int
+	// Used is the quantity of the other products of the warehouse
+	Used int
+}
+
+// Remaining returns the quantity that still fits in the warehouse.
+func (e *WarehouseCapacityError) Remaining() int {
+	return max(e.Capacity-e.Used, 0)
+}
+
+// Error returns the message of the error.
+func (e *WarehouseCapacityError) Error() string {
+	return fmt.Sprintf("%s: warehouse %d has %d of %d remaining", ErrRepositoryProductWarehouseFull, e.IdWarehouse, e.Remaining(), e.Capacity)
+}
+
+// Unwrap returns ErrRepositoryProductWarehouseFull.
+func (e *WarehouseCapacityError) Unwrap() error {
+	return ErrRepositoryProductWarehouseFull
+}
+
 // RepositoryProduct is an interface that contains the methods for a product repository
+// A code value is unique per warehouse, not globally: a transfer of part of the stock of a product
+// splits it into a product in each warehouse with the same code value (see RepositoryTransfer).
+// Every write of a product (Save, SaveAll, UpdateOrSave and Update) requires its warehouse to exist
+// and have room for its quantity, and no other product of the warehouse to have its code value.
 type RepositoryProduct interface {
 	FindAll() ([]Product, error)
+	// ForEach calls fn with every product, sorted by id, and stops at the first error of fn
+	ForEach(fn func(p Product) error) (err error)
+	// FindPage returns a page of the products matching q and the number of products matching q
+	FindPage(q ProductQuery) (p []Product, total int, err error)
 	// FindById returns a product by its id
 	FindById(id int) (p Product, err error)
+	// FindByCodeValue returns the products with the code value, one per warehouse holding stock of it
+	FindByCodeValue(code string) (p []Product, err error)
 
-	CountProductsByWarehouseID(id int) (count int, err error)
-	// Save saves a product
+	// Save saves a product at version 1
 	Save(p *Product) (err error)
+	// SaveAll saves the products in a single write, each at version 1
+	// errs has the error of every product that was not saved, nil for the saved ones.
+	// When atomic, no product is saved if any of them fails.
+	SaveAll(p []Product, atomic bool) (errs []error, err error)
 	// UpdateOrSave updates or saves a product
+	// The version of p must be the version of the stored product, or 0 when no product is expected.
 	UpdateOrSave(p *Product) (err error)
-	// Update updates a product
+	// Update updates a product and increments its version
+	// The version of p must be the version of the stored product.
 	Update(p *Product) (err error)
 	// Delete deletes a product
-	Delete(id int) (err error)
+	// The version must be the version of the stored product.
+	Delete(id int, version int) (err error)
+	// UnpublishExpired unpublishes the published products that expired before today
+	UnpublishExpired(today time.Time) (n int, err error)
 }
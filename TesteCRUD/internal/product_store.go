@@ -1,9 +1,18 @@
 package internal
 
+import "errors"
+
+var (
+	// ErrStoreProductModified is returned when the store was modified by someone else since it was last written or first read.
+	ErrStoreProductModified = errors.New("store: products modified externally")
+)
+
 // StoreProduct is an interface for a product store.
 type StoreProduct interface {
 	// ReadAll reads all products from the store.
 	ReadAll() (p map[int]Product, err error)
 	// WriteAll writes all products to the store.
 	WriteAll(p map[int]Product) (err error)
+	// NextId assigns the next product id, concurrent calls never get the same id.
+	NextId() (id int, err error)
 }
\ No newline at end of file
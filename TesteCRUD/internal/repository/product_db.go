@@ -3,7 +3,9 @@ package repository
 import (
 	"app/internal"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -18,45 +20,162 @@ type RepositoryProductDB struct {
 }
 
 func (r *RepositoryProductDB) FindAll() ([]internal.Product, error) {
-	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse FROM products"
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products"
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var products []internal.Product
+	return scanProducts(rows)
+}
+
+// ForEach calls fn with every product, sorted by id, reading them one at a time.
+func (r *RepositoryProductDB) ForEach(fn func(p internal.Product) error) error {
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products ORDER BY id"
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
 	for rows.Next() {
-		var p internal.Product
-		var isPublishedStr string
-		var expirationBytes []byte
+		p, err := scanProduct(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
 
-		// Escaneie os dados retornados, incluindo a coluna expiration como []byte
-		if err := rows.Scan(&p.Id, &p.Name, &p.Quantity, &p.CodeValue, &isPublishedStr, &expirationBytes, &p.Price, &p.IdWarehouse); err != nil {
+// scanProducts reads every row of a products query.
+func scanProducts(rows *sql.Rows) ([]internal.Product, error) {
+	var products []internal.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
 			return nil, err
 		}
 
-		// Trate a coluna is_published
-		p.IsPublished = (isPublishedStr == "1")
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// scanProduct reads the current row of a products query.
+func scanProduct(rows *sql.Rows) (p internal.Product, err error) {
+	var isPublishedStr string
+	var expirationBytes []byte
+
+	// Escaneie os dados retornados, incluindo a coluna expiration como []byte
+	if err := rows.Scan(&p.Id, &p.Name, &p.Quantity, &p.CodeValue, &isPublishedStr, &expirationBytes, &p.Price, &p.IdWarehouse, &p.Version); err != nil {
+		return p, err
+	}
+
+	// Trate a coluna is_published
+	p.IsPublished = (isPublishedStr == "1")
 
-		// Converta o expirationBytes para time.Time
-		if len(expirationBytes) > 0 {
-			expirationString := string(expirationBytes)
-			expirationTime, err := time.Parse("2006-01-02", expirationString)
-			if err != nil {
-				return nil, fmt.Errorf("invalid expiration format for product ID %d: %v", p.Id, err)
-			}
-			p.Expiration = expirationTime
+	// Converta o expirationBytes para time.Time
+	if len(expirationBytes) > 0 {
+		expirationString := string(expirationBytes)
+		expirationTime, err := time.Parse("2006-01-02", expirationString)
+		if err != nil {
+			return p, fmt.Errorf("invalid expiration format for product ID %d: %v", p.Id, err)
 		}
+		p.Expiration = expirationTime
+	}
 
-		products = append(products, p)
+	return p, nil
+}
+
+// productColumns maps the sort fields to the columns of the products table.
+var productColumns = map[string]string{
+	"id":           "id",
+	"name":         "name",
+	"quantity":     "quantity",
+	"code_value":   "code_value",
+	"is_published": "is_published",
+	"expiration":   "expiration",
+	"price":        "price",
+	"id_warehouse": "id_warehouse",
+}
+
+// FindPage returns a page of the products matching q and the number of products matching q.
+func (r *RepositoryProductDB) FindPage(q internal.ProductQuery) (p []internal.Product, total int, err error) {
+	column, ok := productColumns[q.Sort]
+	if !ok || q.Page < 1 || q.PageSize < 1 {
+		err = internal.ErrRepositoryProductQueryInvalid
+		return
 	}
 
-	return products, nil
+	// filters
+	var conds []string
+	var args []any
+	if q.IsPublished != nil {
+		isPublishedStr := "0"
+		if *q.IsPublished {
+			isPublishedStr = "1"
+		}
+		conds = append(conds, "is_published = ?")
+		args = append(args, isPublishedStr)
+	}
+	if q.IdWarehouse != nil {
+		conds = append(conds, "id_warehouse = ?")
+		args = append(args, *q.IdWarehouse)
+	}
+	if q.PriceMin != nil {
+		conds = append(conds, "price >= ?")
+		args = append(args, *q.PriceMin)
+	}
+	if q.PriceMax != nil {
+		conds = append(conds, "price <= ?")
+		args = append(args, *q.PriceMax)
+	}
+	if !q.ExpirationFrom.IsZero() {
+		conds = append(conds, "expiration >= ?")
+		args = append(args, q.ExpirationFrom.Format(time.DateOnly))
+	}
+	if !q.ExpirationTo.IsZero() {
+		conds = append(conds, "expiration <= ?")
+		args = append(args, q.ExpirationTo.Format(time.DateOnly))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	// total
+	err = r.db.QueryRow("SELECT COUNT(*) FROM products"+where, args...).Scan(&total)
+	if err != nil {
+		return
+	}
+
+	// page
+	dir := "ASC"
+	if q.Desc {
+		dir = "DESC"
+	}
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products" + where +
+		fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT ? OFFSET ?", column, dir, dir)
+	rows, err := r.db.Query(query, append(args, q.PageSize, (q.Page-1)*q.PageSize)...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	p, err = scanProducts(rows)
+	return
 }
 
 func (r *RepositoryProductDB) FindById(id int) (p internal.Product, err error) {
-	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse FROM products WHERE id = ?"
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products WHERE id = ?"
 	row := r.db.QueryRow(query, id)
 
 	var isPublishedStr string
@@ -68,11 +187,12 @@ func (r *RepositoryProductDB) FindById(id int) (p internal.Product, err error) {
 		&isPublishedStr,
 		&expirationBytes,
 		&p.Price,
-		&p.IdWarehouse)
+		&p.IdWarehouse,
+		&p.Version)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return p, fmt.Errorf("product not found with id: %d", id)
+			return p, fmt.Errorf("%w: id %d", internal.ErrRepositoryProductNotFound, id)
 		}
 		return p, err
 	}
@@ -90,73 +210,223 @@ func (r *RepositoryProductDB) FindById(id int) (p internal.Product, err error) {
 	return p, nil
 }
 
-func (r *RepositoryProductDB) CountProductsByWarehouseID(id int) (count int, err error) {
-	query := `
-        SELECT COUNT(p.id) 
-        FROM warehouses w 
-        LEFT JOIN products p ON w.id = p.id_warehouse 
-        WHERE w.id = ?`
+func (r *RepositoryProductDB) FindByCodeValue(code string) ([]internal.Product, error) {
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products WHERE code_value = ? ORDER BY id_warehouse, id"
+	rows, err := r.db.Query(query, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	err = r.db.QueryRow(query, id).Scan(&count)
+	products, err := scanProducts(rows)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, fmt.Errorf("%w: code value %s", internal.ErrRepositoryProductNotFound, code)
 	}
 
-	return count, nil
+	return products, nil
 }
 
 func (r *RepositoryProductDB) Save(p *internal.Product) (err error) {
-	var lastID int
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = r.save(tx, p)
+	if err != nil {
+		return err
+	}
 
-	// Primeiro, buscar o maior ID atual
-	query := "SELECT COALESCE(MAX(id), 0) FROM products"
-	err = r.db.QueryRow(query).Scan(&lastID)
+	return tx.Commit()
+}
+
+// SaveAll saves the products in one transaction.
+// A product whose warehouse is missing or full, or whose code value is taken, is reported in errs;
+// any other error aborts the whole transaction.
+func (r *RepositoryProductDB) SaveAll(ps []internal.Product, atomic bool) (errs []error, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	errs = make([]error, len(ps))
+	var failed bool
+	for i := range ps {
+		errs[i] = r.save(tx, &ps[i])
+		if errs[i] == nil {
+			continue
+		}
+		if !isProductWriteError(errs[i]) {
+			err = errs[i]
+			return nil, err
+		}
+		failed = true
+		ps[i].Id, ps[i].Version = 0, 0
+	}
+
+	// all or nothing
+	if atomic && failed {
+		for i := range ps {
+			ps[i].Id, ps[i].Version = 0, 0
+		}
+		return errs, tx.Rollback()
+	}
+
+	return errs, tx.Commit()
+}
+
+// isProductWriteError reports whether err rejects a single product write, rather than failing the storage.
+func isProductWriteError(err error) bool {
+	return errors.Is(err, internal.ErrRepositoryWarehouseNotFound) ||
+		errors.Is(err, internal.ErrRepositoryProductWarehouseFull) ||
+		errors.Is(err, internal.ErrRepositoryProductDuplicated)
+}
+
+// save inserts a product once its warehouse has room for it.
+// The id is assigned by AUTO_INCREMENT, so concurrent saves never share one.
+func (r *RepositoryProductDB) save(tx *sql.Tx, p *internal.Product) (err error) {
+	// O produto ainda não existe, nenhum outro produto é ele mesmo
+	p.Id = 0
+
+	// Reserva espaço no depósito
+	err = reserveWarehouse(tx, p)
 	if err != nil {
 		return err
 	}
 
-	// Incrementa o último ID
-	p.Id = lastID + 1
+	// Verifica se o código já existe no depósito
+	err = checkCodeValue(tx, p)
+	if err != nil {
+		return err
+	}
 
 	// Prepare o comando de inserção
-	insertQuery := "INSERT INTO products (id, name, quantity, code_value, is_published, expiration, price, id_warehouse) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	insertQuery := "INSERT INTO products (name, quantity, code_value, is_published, expiration, price, id_warehouse, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
 	isPublishedStr := "0" // padrão para não publicado
 	if p.IsPublished {
 		isPublishedStr = "1"
 	}
 
 	// Inserindo o produto no banco de dados
-	_, err = r.db.Exec(insertQuery,
-		p.Id, // Agora o ID é definido corretamente
+	res, err := tx.Exec(insertQuery,
 		p.Name,
 		p.Quantity,
 		p.CodeValue,
 		isPublishedStr,
 		p.Expiration,
 		p.Price,
-		p.IdWarehouse)
-	return err
+		p.IdWarehouse,
+		1)
+	if err != nil {
+		return err
+	}
+
+	// O ID é o gerado pelo banco de dados
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.Id = int(id)
+	p.Version = 1
+	return nil
 }
 
 func (r *RepositoryProductDB) UpdateOrSave(p *internal.Product) (err error) {
-	// Check if the product exists
-	_, err = r.FindById(p.Id)
+	tx, err := r.db.Begin()
 	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Check if the product exists
+	var id int
+	err = tx.QueryRow("SELECT id FROM products WHERE id = ? FOR UPDATE", p.Id).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows && p.Version != 0:
+		// The product was expected to exist
+		err = fmt.Errorf("%w: id %d was deleted", internal.ErrRepositoryProductVersionMismatch, p.Id)
+	case err == sql.ErrNoRows:
 		// If the product does not exist, save it
-		return r.Save(p)
+		err = r.save(tx, p)
+	case err == nil:
+		// Otherwise, update the existing product
+		err = r.update(tx, p)
+	}
+	if err != nil {
+		return err
 	}
-	// Otherwise, update the existing product
-	return r.Update(p)
+
+	return tx.Commit()
 }
 
 func (r *RepositoryProductDB) Update(p *internal.Product) (err error) {
-	query := "UPDATE products SET name = ?, quantity = ?, code_value = ?, is_published = ?, expiration = ?, price = ?, id_warehouse = ? WHERE id = ?"
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = r.update(tx, p)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// update updates a product at the version of p once its warehouse has room for it.
+func (r *RepositoryProductDB) update(tx *sql.Tx, p *internal.Product) (err error) {
+	// lock the product and check its version
+	var version int
+	err = tx.QueryRow("SELECT version FROM products WHERE id = ? FOR UPDATE", p.Id).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: id %d", internal.ErrRepositoryProductNotFound, p.Id)
+		}
+		return err
+	}
+	if version != p.Version {
+		return fmt.Errorf("%w: id %d is at version %d, not %d", internal.ErrRepositoryProductVersionMismatch, p.Id, version, p.Version)
+	}
+
+	err = reserveWarehouse(tx, p)
+	if err != nil {
+		return err
+	}
+
+	err = checkCodeValue(tx, p)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE products SET name = ?, quantity = ?, code_value = ?, is_published = ?, expiration = ?, price = ?, id_warehouse = ?, version = version + 1 WHERE id = ?"
 	isPublishedStr := "0"
 	if p.IsPublished {
 		isPublishedStr = "1"
 	}
 
-	_, err = r.db.Exec(query,
+	_, err = tx.Exec(query,
 		p.Name,
 		p.Quantity,
 		p.CodeValue,
@@ -165,11 +435,106 @@ func (r *RepositoryProductDB) Update(p *internal.Product) (err error) {
 		p.Price,
 		p.IdWarehouse,
 		p.Id)
+	if err != nil {
+		return err
+	}
+
+	p.Version++
 	return
 }
 
-func (r *RepositoryProductDB) Delete(id int) (err error) {
-	query := "DELETE FROM products WHERE id = ?"
-	_, err = r.db.Exec(query, id)
-	return
+// checkCodeValue checks no other product of the warehouse of p has the code value of p.
+// It must run after reserveWarehouse, whose lock keeps other writes to the warehouse waiting.
+func checkCodeValue(tx *sql.Tx, p *internal.Product) (err error) {
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM products WHERE code_value = ? AND id_warehouse = ? AND id <> ?", p.CodeValue, p.IdWarehouse, p.Id).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%w: code value %s in warehouse %d", internal.ErrRepositoryProductDuplicated, p.CodeValue, p.IdWarehouse)
+	}
+	return nil
+}
+
+// reserveWarehouse locks the warehouse of p until the end of tx and checks the quantity of p fits in it,
+// besides the quantity of the other products of the warehouse.
+// A write that does not raise the quantity of p in the warehouse is accepted even when it is over its capacity,
+// so a warehouse filled before its capacity was lowered can still be written to.
+// Concurrent writes to the same warehouse wait for the lock, so they cannot overfill it.
+func reserveWarehouse(tx *sql.Tx, p *internal.Product) (err error) {
+	var capacity int
+	err = tx.QueryRow("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE", p.IdWarehouse).Scan(&capacity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, p.IdWarehouse)
+		}
+		return err
+	}
+
+	var used int
+	err = tx.QueryRow("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?", p.IdWarehouse, p.Id).Scan(&used)
+	if err != nil {
+		return err
+	}
+
+	if used+p.Quantity <= capacity {
+		return nil
+	}
+
+	// quantity of p in the warehouse before the write, none for a new product or one moved in
+	var previous int
+	if p.Id != 0 {
+		err = tx.QueryRow("SELECT quantity FROM products WHERE id = ? AND id_warehouse = ?", p.Id, p.IdWarehouse).Scan(&previous)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	if p.Quantity > previous {
+		return &internal.WarehouseCapacityError{IdWarehouse: p.IdWarehouse, Capacity: capacity, Used: used}
+	}
+	return nil
+}
+
+func (r *RepositoryProductDB) Delete(id int, version int) (err error) {
+	query := "DELETE FROM products WHERE id = ? AND version = ?"
+	res, err := r.db.Exec(query, id, version)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	// nothing deleted, either the product does not exist or it is at another version
+	var current int
+	err = r.db.QueryRow("SELECT version FROM products WHERE id = ?", id).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: id %d", internal.ErrRepositoryProductNotFound, id)
+		}
+		return err
+	}
+	return fmt.Errorf("%w: id %d is at version %d, not %d", internal.ErrRepositoryProductVersionMismatch, id, current, version)
+}
+
+func (r *RepositoryProductDB) UnpublishExpired(today time.Time) (n int, err error) {
+	query := "UPDATE products SET is_published = '0', version = version + 1 WHERE is_published = '1' AND expiration < ?"
+	res, err := r.db.Exec(query, today.Format(time.DateOnly))
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rows), nil
 }
@@ -1,8 +1,11 @@
 package repository_test
 
 import (
+	"app/internal"
 	"app/internal/repository"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -13,11 +16,11 @@ func TestProductRepository_GetAll(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "id_warehouse"}).
-		AddRow(1, "Corn Shoots", 244, "0009-1111", "0", "2022-01-08", 23.27, 1).
-		AddRow(2, "Shrimp - Baby, Cold Water", 174, "49288-0877", "0", "2022-08-04", 52.12, 1)
+	rows := sqlmock.NewRows([]string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "id_warehouse", "version"}).
+		AddRow(1, "Corn Shoots", 244, "0009-1111", "0", "2022-01-08", 23.27, 1, 1).
+		AddRow(2, "Shrimp - Baby, Cold Water", 174, "49288-0877", "0", "2022-08-04", 52.12, 1, 1)
 
-	mock.ExpectQuery("SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse FROM products").
+	mock.ExpectQuery("SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products").
 		WillReturnRows(rows)
 
 	repo := repository.NewRepositoryProductDB(db)
@@ -44,3 +47,439 @@ func TestProductRepository_GetAll(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestProductRepository_FindPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	published := true
+	priceMin := 10.0
+	q := internal.ProductQuery{
+		IsPublished: &published,
+		PriceMin:    &priceMin,
+		Sort:        "price",
+		Desc:        true,
+		Page:        2,
+		PageSize:    1,
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM products WHERE is_published = ? AND price >= ?")).
+		WithArgs("1", 10.0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	rows := sqlmock.NewRows([]string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "id_warehouse", "version"}).
+		AddRow(9, "Beer - Labatt Blue", 23, "48951-1215", "1", "2022-06-23", 32.99, 1, 1)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM products WHERE is_published = ? AND price >= ? ORDER BY price DESC, id DESC LIMIT ? OFFSET ?")).
+		WithArgs("1", 10.0, 1, 1).
+		WillReturnRows(rows)
+
+	repo := repository.NewRepositoryProductDB(db)
+	products, total, err := repo.FindPage(q)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, products, 1)
+	assert.Equal(t, 9, products[0].Id)
+	assert.True(t, products[0].IsPublished)
+
+	_, _, err = repo.FindPage(internal.ProductQuery{Sort: "unknown", Page: 1, PageSize: 1})
+	assert.ErrorIs(t, err, internal.ErrRepositoryProductQueryInvalid)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_Save(t *testing.T) {
+	t.Run("success - product fits in warehouse", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?")).
+			WithArgs(1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(60))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM products WHERE code_value = ? AND id_warehouse = ? AND id <> ?")).
+			WithArgs("45802-327", 1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("INSERT INTO products").
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 40, CodeValue: "45802-327"}, IdWarehouse: 1}
+		err = repo.Save(&p)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 11, p.Id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - warehouse capacity exceeded", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?")).
+			WithArgs(1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(90))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 40}, IdWarehouse: 1}
+		err = repo.Save(&p)
+
+		var errCapacity *internal.WarehouseCapacityError
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductWarehouseFull)
+		assert.ErrorAs(t, err, &errCapacity)
+		assert.Equal(t, 10, errCapacity.Remaining())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - code value duplicated in warehouse", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?")).
+			WithArgs(1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(60))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM products WHERE code_value = ? AND id_warehouse = ? AND id <> ?")).
+			WithArgs("45802-327", 1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 40, CodeValue: "45802-327"}, IdWarehouse: 1}
+		err = repo.Save(&p)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductDuplicated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - warehouse not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{ProductAttributes: internal.ProductAttributes{Quantity: 1}, IdWarehouse: 9}
+		err = repo.Save(&p)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_SaveAll(t *testing.T) {
+	// expectSave expects the queries of checking a new product fits in warehouse 1 with used units
+	expectSave := func(mock sqlmock.Sqlmock, used int) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?")).
+			WithArgs(1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(used))
+	}
+	products := func() []internal.Product {
+		return []internal.Product{
+			{ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 40, CodeValue: "45802-327"}, IdWarehouse: 1},
+			{ProductAttributes: internal.ProductAttributes{Name: "Corn Shoots", Quantity: 40, CodeValue: "0009-1111"}, IdWarehouse: 1},
+		}
+	}
+
+	t.Run("success - best effort saves the products that fit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		expectSave(mock, 20)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM products WHERE code_value = ? AND id_warehouse = ? AND id <> ?")).
+			WithArgs("45802-327", 1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("INSERT INTO products").
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		expectSave(mock, 80)
+		mock.ExpectCommit()
+
+		repo := repository.NewRepositoryProductDB(db)
+		ps := products()
+		errs, err := repo.SaveAll(ps, false)
+
+		assert.NoError(t, err)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], internal.ErrRepositoryProductWarehouseFull)
+		assert.Equal(t, 11, ps[0].Id)
+		assert.Equal(t, 0, ps[1].Id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - atomic rolls back every product", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		expectSave(mock, 20)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM products WHERE code_value = ? AND id_warehouse = ? AND id <> ?")).
+			WithArgs("45802-327", 1, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("INSERT INTO products").
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		expectSave(mock, 80)
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryProductDB(db)
+		ps := products()
+		errs, err := repo.SaveAll(ps, true)
+
+		assert.NoError(t, err)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], internal.ErrRepositoryProductWarehouseFull)
+		assert.Equal(t, 0, ps[0].Id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_Update(t *testing.T) {
+	t.Run("success - version incremented", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM products WHERE id = ? FOR UPDATE")).
+			WithArgs(8).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?")).
+			WithArgs(1, 8).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(60))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM products WHERE code_value = ? AND id_warehouse = ? AND id <> ?")).
+			WithArgs("45802-327", 1, 8).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE products SET name = ?, quantity = ?, code_value = ?, is_published = ?, expiration = ?, price = ?, id_warehouse = ?, version = version + 1 WHERE id = ?")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{Id: 8, ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 40, CodeValue: "45802-327"}, IdWarehouse: 1, Version: 3}
+		err = repo.Update(&p)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, p.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - quantity not increased in a full warehouse", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM products WHERE id = ? FOR UPDATE")).
+			WithArgs(8).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?")).
+			WithArgs(1, 8).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(120))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT quantity FROM products WHERE id = ? AND id_warehouse = ?")).
+			WithArgs(8, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(40))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM products WHERE code_value = ? AND id_warehouse = ? AND id <> ?")).
+			WithArgs("45802-327", 1, 8).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE products SET name = ?, quantity = ?, code_value = ?, is_published = ?, expiration = ?, price = ?, id_warehouse = ?, version = version + 1 WHERE id = ?")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{Id: 8, ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 30, CodeValue: "45802-327"}, IdWarehouse: 1, Version: 3}
+		err = repo.Update(&p)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, p.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - quantity increased in a full warehouse", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM products WHERE id = ? FOR UPDATE")).
+			WithArgs(8).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ? AND id <> ?")).
+			WithArgs(1, 8).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(120))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT quantity FROM products WHERE id = ? AND id_warehouse = ?")).
+			WithArgs(8, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(40))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{Id: 8, ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 41, CodeValue: "45802-327"}, IdWarehouse: 1, Version: 3}
+		err = repo.Update(&p)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductWarehouseFull)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - version mismatch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM products WHERE id = ? FOR UPDATE")).
+			WithArgs(8).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryProductDB(db)
+		p := internal.Product{Id: 8, ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Quantity: 40}, IdWarehouse: 1, Version: 3}
+		err = repo.Update(&p)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductVersionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_Delete(t *testing.T) {
+	t.Run("success - product deleted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM products WHERE id = ? AND version = ?")).
+			WithArgs(8, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := repository.NewRepositoryProductDB(db)
+		err = repo.Delete(8, 3)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - version mismatch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM products WHERE id = ? AND version = ?")).
+			WithArgs(8, 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM products WHERE id = ?")).
+			WithArgs(8).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+
+		repo := repository.NewRepositoryProductDB(db)
+		err = repo.Delete(8, 3)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductVersionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM products WHERE id = ? AND version = ?")).
+			WithArgs(8, 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM products WHERE id = ?")).
+			WithArgs(8).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+		repo := repository.NewRepositoryProductDB(db)
+		err = repo.Delete(8, 3)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_FindByCodeValue(t *testing.T) {
+	t.Run("success - products in every warehouse", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "id_warehouse", "version"}).
+			AddRow(8, "Persimmons", 200, "45802-327", "0", "2021-04-14", 60.65, 1, 1).
+			AddRow(11, "Persimmons", 38, "45802-327", "0", "2021-04-14", 60.65, 2, 1)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products WHERE code_value = ? ORDER BY id_warehouse, id")).
+			WithArgs("45802-327").
+			WillReturnRows(rows)
+
+		repo := repository.NewRepositoryProductDB(db)
+		products, err := repo.FindByCodeValue("45802-327")
+
+		assert.NoError(t, err)
+		assert.Len(t, products, 2)
+		assert.Equal(t, 2, products[1].IdWarehouse)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products WHERE code_value = ?")).
+			WithArgs("0000-000").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "id_warehouse", "version"}))
+
+		repo := repository.NewRepositoryProductDB(db)
+		_, err = repo.FindByCodeValue("0000-000")
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestProductRepository_UnpublishExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE products SET is_published = '0', version = version + 1 WHERE is_published = '1' AND expiration < ?")).
+		WithArgs("2022-06-01").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	repo := repository.NewRepositoryProductDB(db)
+	n, err := repo.UnpublishExpired(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,11 +1,21 @@
 package repository
 
-import "app/internal"
+import (
+	"app/internal"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
 
 // NewRepositoryProductStore creates a new repository for products.
-func NewRepositoryProductStore(st internal.StoreProduct) (r *RepositoryProductStore) {
+func NewRepositoryProductStore(st internal.StoreProduct, rpWare internal.RepositoryWarehouse) (r *RepositoryProductStore) {
 	r = &RepositoryProductStore{
-		st: st,
+		st:     st,
+		rpWare: rpWare,
+		mu:     storeLock(rpWare),
 	}
 	return
 }
@@ -14,6 +24,186 @@ func NewRepositoryProductStore(st internal.StoreProduct) (r *RepositoryProductSt
 type RepositoryProductStore struct {
 	// st is the underlying store.
 	st internal.StoreProduct
+	// rpWare is the repository for the warehouses of the products.
+	rpWare internal.RepositoryWarehouse
+	// mu serializes the writes, so the capacity of a warehouse is checked against the products written.
+	mu *sync.Mutex
+}
+
+// storeLock returns the lock of the writes of the products.
+// Deleting a warehouse of a RepositoryWarehouseStore moves its products, so the repositories
+// on its stores share its lock, otherwise the lock is a new one.
+func storeLock(rpWare internal.RepositoryWarehouse) *sync.Mutex {
+	if rp, ok := rpWare.(*RepositoryWarehouseStore); ok {
+		return rp.mu
+	}
+	return &sync.Mutex{}
+}
+
+// reserveWarehouse checks the warehouse of p exists and the quantity of p fits in it,
+// besides the quantity of the other products of ps in the warehouse,
+// and that no other product of ps in the warehouse has the code value of p.
+// A write that does not raise the quantity of p in the warehouse is accepted even when it is over its capacity,
+// so a warehouse filled before its capacity was lowered can still be written to.
+func (r *RepositoryProductStore) reserveWarehouse(ps map[int]internal.Product, p internal.Product) (err error) {
+	wh, err := r.rpWare.FindById(p.IdWarehouse)
+	if err != nil {
+		return
+	}
+
+	var used int
+	for _, v := range ps {
+		if v.IdWarehouse == wh.Id && v.Id != p.Id {
+			used += v.Quantity
+		}
+	}
+
+	// quantity of p in the warehouse before the write, none for a new product or one moved in
+	var previous int
+	if v, ok := ps[p.Id]; ok && v.IdWarehouse == wh.Id {
+		previous = v.Quantity
+	}
+	if used+p.Quantity > wh.Capacity && p.Quantity > previous {
+		err = &internal.WarehouseCapacityError{IdWarehouse: wh.Id, Capacity: wh.Capacity, Used: used}
+		return
+	}
+
+	// check code value
+	for _, v := range ps {
+		if v.CodeValue == p.CodeValue && v.IdWarehouse == p.IdWarehouse && v.Id != p.Id {
+			err = fmt.Errorf("%w: code value %s in warehouse %d", internal.ErrRepositoryProductDuplicated, p.CodeValue, p.IdWarehouse)
+			return
+		}
+	}
+	return
+}
+
+// nextId assigns the next id of the store not taken by a product of ps.
+// Products added to the store by other means may already hold ids of the sequence, those are skipped.
+func (r *RepositoryProductStore) nextId(ps map[int]internal.Product) (id int, err error) {
+	return nextProductId(r.st, ps)
+}
+
+// nextProductId assigns the next id of st not taken by a product of ps.
+func nextProductId(st internal.StoreProduct, ps map[int]internal.Product) (id int, err error) {
+	for {
+		id, err = st.NextId()
+		if err != nil {
+			return
+		}
+		if _, ok := ps[id]; !ok {
+			return
+		}
+	}
+}
+
+// FindAll finds all products, sorted by id.
+func (r *RepositoryProductStore) FindAll() (p []internal.Product, err error) {
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// sort products
+	for _, v := range ps {
+		p = append(p, v)
+	}
+	slices.SortFunc(p, func(a, b internal.Product) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	return
+}
+
+// ForEach calls fn with every product, sorted by id.
+func (r *RepositoryProductStore) ForEach(fn func(p internal.Product) error) (err error) {
+	ps, err := r.FindAll()
+	if err != nil {
+		return
+	}
+
+	for _, p := range ps {
+		err = fn(p)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// productCompare are the comparison functions of the sort fields.
+var productCompare = map[string]func(a, b internal.Product) int{
+	"id":         func(a, b internal.Product) int { return cmp.Compare(a.Id, b.Id) },
+	"name":       func(a, b internal.Product) int { return strings.Compare(a.Name, b.Name) },
+	"quantity":   func(a, b internal.Product) int { return cmp.Compare(a.Quantity, b.Quantity) },
+	"code_value": func(a, b internal.Product) int { return strings.Compare(a.CodeValue, b.CodeValue) },
+	"is_published": func(a, b internal.Product) int {
+		switch {
+		case a.IsPublished == b.IsPublished:
+			return 0
+		case b.IsPublished:
+			return -1
+		default:
+			return 1
+		}
+	},
+	"expiration":   func(a, b internal.Product) int { return a.Expiration.Compare(b.Expiration) },
+	"price":        func(a, b internal.Product) int { return cmp.Compare(a.Price, b.Price) },
+	"id_warehouse": func(a, b internal.Product) int { return cmp.Compare(a.IdWarehouse, b.IdWarehouse) },
+}
+
+// FindPage finds a page of the products matching q and the number of products matching q.
+func (r *RepositoryProductStore) FindPage(q internal.ProductQuery) (p []internal.Product, total int, err error) {
+	compare, ok := productCompare[q.Sort]
+	if !ok || q.Page < 1 || q.PageSize < 1 {
+		err = internal.ErrRepositoryProductQueryInvalid
+		return
+	}
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// filter products
+	var matches []internal.Product
+	for _, v := range ps {
+		switch {
+		case q.IsPublished != nil && v.IsPublished != *q.IsPublished:
+		case q.IdWarehouse != nil && v.IdWarehouse != *q.IdWarehouse:
+		case q.PriceMin != nil && v.Price < *q.PriceMin:
+		case q.PriceMax != nil && v.Price > *q.PriceMax:
+		case !q.ExpirationFrom.IsZero() && v.Expiration.Before(q.ExpirationFrom):
+		case !q.ExpirationTo.IsZero() && v.Expiration.After(q.ExpirationTo):
+		default:
+			matches = append(matches, v)
+		}
+	}
+	total = len(matches)
+
+	// sort products
+	slices.SortFunc(matches, func(a, b internal.Product) int {
+		c := compare(a, b)
+		if c == 0 {
+			c = cmp.Compare(a.Id, b.Id)
+		}
+		if q.Desc {
+			c = -c
+		}
+		return c
+	})
+
+	// page products
+	start := (q.Page - 1) * q.PageSize
+	if start >= total {
+		return
+	}
+	end := min(start+q.PageSize, total)
+	p = matches[start:end]
+
+	return
 }
 
 // FindById finds a product by id.
@@ -34,30 +224,57 @@ func (r *RepositoryProductStore) FindById(id int) (p internal.Product, err error
 	return
 }
 
-// Save saves a product.
-func (r *RepositoryProductStore) Save(p *internal.Product) (err error) {
+// FindByCodeValue finds the products with the code value, sorted by warehouse.
+func (r *RepositoryProductStore) FindByCodeValue(code string) (p []internal.Product, err error) {
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
 		return
 	}
 
-	// find max id
-	var maxId int
-	var cc int
-	for k := range ps {
-		cc++
-		if cc == 1 {
-			maxId = k
-			continue
+	// find products
+	for _, v := range ps {
+		if v.CodeValue == code {
+			p = append(p, v)
 		}
-		if k > maxId {
-			maxId = k
+	}
+	if len(p) == 0 {
+		err = internal.ErrRepositoryProductNotFound
+		return
+	}
+	slices.SortFunc(p, func(a, b internal.Product) int {
+		if c := cmp.Compare(a.IdWarehouse, b.IdWarehouse); c != 0 {
+			return c
 		}
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	return
+}
+
+// Save saves a product.
+func (r *RepositoryProductStore) Save(p *internal.Product) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
 	}
 
 	// set id
-	(*p).Id = maxId + 1
+	(*p).Id, err = r.nextId(ps)
+	if err != nil {
+		return
+	}
+	(*p).Version = 1
+
+	// check warehouse
+	err = r.reserveWarehouse(ps, *p)
+	if err != nil {
+		return
+	}
 
 	// add product
 	ps[p.Id] = *p
@@ -71,8 +288,64 @@ func (r *RepositoryProductStore) Save(p *internal.Product) (err error) {
 	return
 }
 
+// SaveAll saves the products in a single write of the store.
+// A product whose warehouse is missing or full, or whose code value is taken, is reported in errs.
+func (r *RepositoryProductStore) SaveAll(p []internal.Product, atomic bool) (errs []error, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// add products
+	errs = make([]error, len(p))
+	var failed bool
+	for i := range p {
+		// check warehouse, the product is not in ps yet
+		p[i].Id = 0
+		errs[i] = r.reserveWarehouse(ps, p[i])
+		if errs[i] != nil {
+			if !isProductWriteError(errs[i]) {
+				return nil, errs[i]
+			}
+			failed = true
+			continue
+		}
+
+		// set id
+		p[i].Id, err = r.nextId(ps)
+		if err != nil {
+			return nil, err
+		}
+		p[i].Version = 1
+		ps[p[i].Id] = p[i]
+	}
+
+	// all or nothing
+	if atomic && failed {
+		for i := range p {
+			p[i].Id, p[i].Version = 0, 0
+		}
+		return
+	}
+
+	// write all products
+	err = r.st.WriteAll(ps)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
 // UpdateOrSave updates or saves a product.
 func (r *RepositoryProductStore) UpdateOrSave(p *internal.Product) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
@@ -80,27 +353,40 @@ func (r *RepositoryProductStore) UpdateOrSave(p *internal.Product) (err error) {
 	}
 
 	// update product
-	_, ok := ps[p.Id]
-	switch ok {
-	case true:
+	current, ok := ps[p.Id]
+	switch {
+	case ok:
+		// check version
+		if current.Version != p.Version {
+			err = fmt.Errorf("%w: id %d is at version %d, not %d", internal.ErrRepositoryProductVersionMismatch, p.Id, current.Version, p.Version)
+			return
+		}
+
+		// check warehouse
+		err = r.reserveWarehouse(ps, *p)
+		if err != nil {
+			return
+		}
+
+		(*p).Version++
 		ps[p.Id] = *p
+	case p.Version != 0:
+		// the product was expected to exist
+		err = fmt.Errorf("%w: id %d was deleted", internal.ErrRepositoryProductVersionMismatch, p.Id)
+		return
 	default:
-		// find max id
-		var maxId int
-		var cc int
-		for k := range ps {
-			cc++
-			if cc == 1 {
-				maxId = k
-				continue
-			}
-			if k > maxId {
-				maxId = k
-			}
+		// set id
+		(*p).Id, err = r.nextId(ps)
+		if err != nil {
+			return
 		}
+		(*p).Version = 1
 
-		// set id
-		(*p).Id = maxId + 1
+		// check warehouse
+		err = r.reserveWarehouse(ps, *p)
+		if err != nil {
+			return
+		}
 
 		// add product
 		ps[p.Id] = *p
@@ -117,6 +403,9 @@ func (r *RepositoryProductStore) UpdateOrSave(p *internal.Product) (err error) {
 
 // Update updates a product.
 func (r *RepositoryProductStore) Update(p *internal.Product) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
@@ -124,13 +413,26 @@ func (r *RepositoryProductStore) Update(p *internal.Product) (err error) {
 	}
 
 	// update product
-	_, ok := ps[p.Id]
+	current, ok := ps[p.Id]
 	if !ok {
 		err = internal.ErrRepositoryProductNotFound
 		return
 	}
 
+	// check version
+	if current.Version != p.Version {
+		err = fmt.Errorf("%w: id %d is at version %d, not %d", internal.ErrRepositoryProductVersionMismatch, p.Id, current.Version, p.Version)
+		return
+	}
+
+	// check warehouse
+	err = r.reserveWarehouse(ps, *p)
+	if err != nil {
+		return
+	}
+
 	// update product
+	(*p).Version++
 	ps[p.Id] = *p
 
 	// write all products
@@ -142,8 +444,11 @@ func (r *RepositoryProductStore) Update(p *internal.Product) (err error) {
 	return
 }
 
-// Delete deletes a product.
-func (r *RepositoryProductStore) Delete(id int) (err error) {
+// Delete deletes a product at the version.
+func (r *RepositoryProductStore) Delete(id int, version int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
@@ -151,11 +456,15 @@ func (r *RepositoryProductStore) Delete(id int) (err error) {
 	}
 
 	// delete product
-	_, ok := ps[id]
+	current, ok := ps[id]
 	if !ok {
 		err = internal.ErrRepositoryProductNotFound
 		return
 	}
+	if current.Version != version {
+		err = fmt.Errorf("%w: id %d is at version %d, not %d", internal.ErrRepositoryProductVersionMismatch, id, current.Version, version)
+		return
+	}
 
 	// delete product
 	delete(ps, id)
@@ -167,4 +476,37 @@ func (r *RepositoryProductStore) Delete(id int) (err error) {
 	}
 
 	return
-}
\ No newline at end of file
+}
+
+// UnpublishExpired unpublishes the published products that expired before today.
+func (r *RepositoryProductStore) UnpublishExpired(today time.Time) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// unpublish products
+	for k, v := range ps {
+		if v.IsPublished && v.Expiration.Before(today) {
+			v.IsPublished = false
+			v.Version++
+			ps[k] = v
+			n++
+		}
+	}
+	if n == 0 {
+		return
+	}
+
+	// write all products
+	err = r.st.WriteAll(ps)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryProductStore_UnpublishExpired(t *testing.T) {
+	t.Run("success - published products expired before today", func(t *testing.T) {
+		rpWare, _, stProd := newRepositoriesStore(t)
+		rp := repository.NewRepositoryProductStore(stProd, rpWare)
+
+		n, err := rp.UnpublishExpired(time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		p, err := rp.FindById(1)
+		assert.NoError(t, err)
+		assert.False(t, p.IsPublished)
+		assert.Equal(t, 2, p.Version)
+		p, err = rp.FindById(2)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, p.Version)
+	})
+
+	t.Run("success - expiring today is not expired", func(t *testing.T) {
+		rpWare, _, stProd := newRepositoriesStore(t)
+		rp := repository.NewRepositoryProductStore(stProd, rpWare)
+
+		n, err := rp.UnpublishExpired(time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		p, err := rp.FindById(1)
+		assert.NoError(t, err)
+		assert.True(t, p.IsPublished)
+		assert.Equal(t, 1, p.Version)
+	})
+}
+
+func TestRepositoryProductStore_Update(t *testing.T) {
+	// fill the second warehouse over its capacity of 50
+	overfill := func(t *testing.T, stProd *store.StoreMemory[internal.Product]) {
+		exp := time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC)
+		err := stProd.WriteAll(map[int]internal.Product{
+			2: {Id: 2, ProductAttributes: internal.ProductAttributes{CodeValue: "B", Quantity: 80, Price: 1, Expiration: exp}, IdWarehouse: 2, Version: 1},
+		})
+		assert.NoError(t, err)
+	}
+
+	t.Run("success - quantity not increased in a full warehouse", func(t *testing.T) {
+		rpWare, _, stProd := newRepositoriesStore(t)
+		overfill(t, stProd)
+		rp := repository.NewRepositoryProductStore(stProd, rpWare)
+
+		p, err := rp.FindById(2)
+		assert.NoError(t, err)
+		p.Quantity = 70
+		err = rp.Update(&p)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, p.Version)
+	})
+
+	t.Run("error - quantity increased in a full warehouse", func(t *testing.T) {
+		rpWare, _, stProd := newRepositoriesStore(t)
+		overfill(t, stProd)
+		rp := repository.NewRepositoryProductStore(stProd, rpWare)
+
+		p, err := rp.FindById(2)
+		assert.NoError(t, err)
+		p.Quantity = 81
+		err = rp.Update(&p)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductWarehouseFull)
+	})
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"app/internal"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+func NewRepositoryTransferDB(db *sql.DB) *RepositoryTransferDB {
+	return &RepositoryTransferDB{db: db}
+}
+
+type RepositoryTransferDB struct {
+	db *sql.DB
+}
+
+func (r *RepositoryTransferDB) Save(t *internal.Transfer) (err error) {
+	if t.Quantity <= 0 {
+		return fmt.Errorf("%w: must be greater than 0", internal.ErrRepositoryTransferQuantityInvalid)
+	}
+	if t.ToWarehouse == t.FromWarehouse {
+		return fmt.Errorf("%w: same as the source", internal.ErrRepositoryTransferDestinationInvalid)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// lock the source row
+	var sourceId, stock int
+	err = tx.QueryRow("SELECT id, quantity FROM products WHERE code_value = ? AND id_warehouse = ? ORDER BY id LIMIT 1 FOR UPDATE",
+		t.CodeValue, t.FromWarehouse).Scan(&sourceId, &stock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: code value %s, warehouse %d", internal.ErrRepositoryTransferProductNotFound, t.CodeValue, t.FromWarehouse)
+		}
+		return err
+	}
+	if t.Quantity > stock {
+		return fmt.Errorf("%w: %d available", internal.ErrRepositoryTransferQuantityInvalid, stock)
+	}
+
+	// lock the destination warehouse and check it has room
+	var capacity int
+	err = tx.QueryRow("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE", t.ToWarehouse).Scan(&capacity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: warehouse %d not found", internal.ErrRepositoryTransferDestinationInvalid, t.ToWarehouse)
+		}
+		return err
+	}
+	var used int
+	err = tx.QueryRow("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?", t.ToWarehouse).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used+t.Quantity > capacity {
+		return &internal.WarehouseCapacityError{IdWarehouse: t.ToWarehouse, Capacity: capacity, Used: used}
+	}
+
+	// move the stock
+	var targetId int
+	err = tx.QueryRow("SELECT id FROM products WHERE code_value = ? AND id_warehouse = ? ORDER BY id LIMIT 1 FOR UPDATE",
+		t.CodeValue, t.ToWarehouse).Scan(&targetId)
+	switch {
+	case err == sql.ErrNoRows && t.Quantity == stock:
+		// - the whole row moves
+		_, err = tx.Exec("UPDATE products SET id_warehouse = ?, version = version + 1 WHERE id = ?", t.ToWarehouse, sourceId)
+	case err == sql.ErrNoRows:
+		// - split the row, the new one keeps the attributes of the source and gets an AUTO_INCREMENT id
+		_, err = tx.Exec(`INSERT INTO products (name, quantity, code_value, is_published, expiration, price, id_warehouse)
+			SELECT name, ?, code_value, is_published, expiration, price, ? FROM products WHERE id = ?`,
+			t.Quantity, t.ToWarehouse, sourceId)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE products SET quantity = quantity - ?, version = version + 1 WHERE id = ?", t.Quantity, sourceId)
+	case err == nil:
+		// - merge into the destination row
+		_, err = tx.Exec("UPDATE products SET quantity = quantity + ?, version = version + 1 WHERE id = ?", t.Quantity, targetId)
+		if err != nil {
+			return err
+		}
+		if t.Quantity == stock {
+			_, err = tx.Exec("DELETE FROM products WHERE id = ?", sourceId)
+		} else {
+			_, err = tx.Exec("UPDATE products SET quantity = quantity - ?, version = version + 1 WHERE id = ?", t.Quantity, sourceId)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// record the transfer
+	t.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	res, err := tx.Exec("INSERT INTO transfers (code_value, quantity, from_warehouse, to_warehouse, created_at) VALUES (?, ?, ?, ?, ?)",
+		t.CodeValue, t.Quantity, t.FromWarehouse, t.ToWarehouse, t.CreatedAt.Format(time.DateTime))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	t.Id = int(id)
+
+	return tx.Commit()
+}
+
+func (r *RepositoryTransferDB) FindByWarehouse(id int) ([]internal.Transfer, error) {
+	query := "SELECT id, code_value, quantity, from_warehouse, to_warehouse, created_at FROM transfers WHERE from_warehouse = ? OR to_warehouse = ? ORDER BY created_at DESC, id DESC"
+	rows, err := r.db.Query(query, id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []internal.Transfer
+	for rows.Next() {
+		var t internal.Transfer
+		var createdAtBytes []byte
+		if err := rows.Scan(&t.Id, &t.CodeValue, &t.Quantity, &t.FromWarehouse, &t.ToWarehouse, &createdAtBytes); err != nil {
+			return nil, err
+		}
+
+		t.CreatedAt, err = time.Parse(time.DateTime, string(createdAtBytes))
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_at format for transfer ID %d: %v", t.Id, err)
+		}
+
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
@@ -0,0 +1,141 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferRepository_Save(t *testing.T) {
+	expectSource := func(mock sqlmock.Sqlmock, stock int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, quantity FROM products WHERE code_value = ? AND id_warehouse = ?")).
+			WithArgs("0009-1111", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "quantity"}).AddRow(5, stock))
+	}
+	expectDestination := func(mock sqlmock.Sqlmock, capacity, used int) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(capacity))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(used))
+	}
+
+	t.Run("success - row split", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectSource(mock, 50)
+		expectDestination(mock, 100, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM products WHERE code_value = ? AND id_warehouse = ?")).
+			WithArgs("0009-1111", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec("INSERT INTO products").
+			WithArgs(20, 2, 5).
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE products SET quantity = quantity - ?, version = version + 1 WHERE id = ?")).
+			WithArgs(20, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO transfers").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewRepositoryTransferDB(db)
+		tr := internal.Transfer{CodeValue: "0009-1111", Quantity: 20, FromWarehouse: 1, ToWarehouse: 2}
+		err = repo.Save(&tr)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, tr.Id)
+		assert.False(t, tr.CreatedAt.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - whole stock merged", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectSource(mock, 20)
+		expectDestination(mock, 100, 30)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM products WHERE code_value = ? AND id_warehouse = ?")).
+			WithArgs("0009-1111", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE products SET quantity = quantity + ?, version = version + 1 WHERE id = ?")).
+			WithArgs(20, 8).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM products WHERE id = ?")).
+			WithArgs(5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO transfers").
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewRepositoryTransferDB(db)
+		tr := internal.Transfer{CodeValue: "0009-1111", Quantity: 20, FromWarehouse: 1, ToWarehouse: 2}
+		err = repo.Save(&tr)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - quantity above stock", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectSource(mock, 10)
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryTransferDB(db)
+		tr := internal.Transfer{CodeValue: "0009-1111", Quantity: 20, FromWarehouse: 1, ToWarehouse: 2}
+		err = repo.Save(&tr)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryTransferQuantityInvalid)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - destination full", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectSource(mock, 50)
+		expectDestination(mock, 100, 90)
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryTransferDB(db)
+		tr := internal.Transfer{CodeValue: "0009-1111", Quantity: 20, FromWarehouse: 1, ToWarehouse: 2}
+		err = repo.Save(&tr)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductWarehouseFull)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTransferRepository_FindByWarehouse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "code_value", "quantity", "from_warehouse", "to_warehouse", "created_at"}).
+		AddRow(2, "0009-1111", 20, 2, 1, "2024-03-02 10:00:00").
+		AddRow(1, "0009-1111", 50, 1, 2, "2024-03-01 10:00:00")
+	mock.ExpectQuery(regexp.QuoteMeta("FROM transfers WHERE from_warehouse = ? OR to_warehouse = ?")).
+		WithArgs(1, 1).
+		WillReturnRows(rows)
+
+	repo := repository.NewRepositoryTransferDB(db)
+	transfers, err := repo.FindByWarehouse(1)
+
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 2)
+	assert.Equal(t, 2, transfers[0].FromWarehouse)
+	assert.Equal(t, 2024, transfers[1].CreatedAt.Year())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"app/internal"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// NewRepositoryTransferStore creates a new repository for transfers.
+func NewRepositoryTransferStore(st internal.StoreTransfer, stProd internal.StoreProduct, rpWare internal.RepositoryWarehouse) (r *RepositoryTransferStore) {
+	r = &RepositoryTransferStore{
+		st:     st,
+		stProd: stProd,
+		rpWare: rpWare,
+		mu:     storeLock(rpWare),
+	}
+	return
+}
+
+// RepositoryTransferStore is a repository for transfers.
+type RepositoryTransferStore struct {
+	// st is the underlying store.
+	st internal.StoreTransfer
+	// stProd is the store of the products moved.
+	stProd internal.StoreProduct
+	// rpWare is the repository for the warehouses of the products.
+	rpWare internal.RepositoryWarehouse
+	// mu serializes the writes of the products, so the capacity of the destination is checked against the products written.
+	mu *sync.Mutex
+}
+
+// firstProduct returns the product of ps with the code value in the warehouse, the one with the lowest id if many.
+func firstProduct(ps map[int]internal.Product, code string, idWarehouse int) (p internal.Product, ok bool) {
+	for _, v := range ps {
+		if v.CodeValue == code && v.IdWarehouse == idWarehouse && (!ok || v.Id < p.Id) {
+			p, ok = v, true
+		}
+	}
+	return
+}
+
+// Save moves the quantity of the product from the source to the destination warehouse and records the transfer.
+func (r *RepositoryTransferStore) Save(t *internal.Transfer) (err error) {
+	if t.Quantity <= 0 {
+		return fmt.Errorf("%w: must be greater than 0", internal.ErrRepositoryTransferQuantityInvalid)
+	}
+	if t.ToWarehouse == t.FromWarehouse {
+		return fmt.Errorf("%w: same as the source", internal.ErrRepositoryTransferDestinationInvalid)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// read all products
+	ps, err := r.stProd.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// source row
+	source, ok := firstProduct(ps, t.CodeValue, t.FromWarehouse)
+	if !ok {
+		return fmt.Errorf("%w: code value %s, warehouse %d", internal.ErrRepositoryTransferProductNotFound, t.CodeValue, t.FromWarehouse)
+	}
+	if t.Quantity > source.Quantity {
+		return fmt.Errorf("%w: %d available", internal.ErrRepositoryTransferQuantityInvalid, source.Quantity)
+	}
+
+	// the destination warehouse must have room
+	wh, err := r.rpWare.FindById(t.ToWarehouse)
+	if err != nil {
+		if errors.Is(err, internal.ErrRepositoryWarehouseNotFound) {
+			return fmt.Errorf("%w: warehouse %d not found", internal.ErrRepositoryTransferDestinationInvalid, t.ToWarehouse)
+		}
+		return
+	}
+	var used int
+	for _, v := range ps {
+		if v.IdWarehouse == wh.Id {
+			used += v.Quantity
+		}
+	}
+	if used+t.Quantity > wh.Capacity {
+		return &internal.WarehouseCapacityError{IdWarehouse: wh.Id, Capacity: wh.Capacity, Used: used}
+	}
+
+	// move the stock
+	target, ok := firstProduct(ps, t.CodeValue, t.ToWarehouse)
+	switch {
+	case !ok && t.Quantity == source.Quantity:
+		// - the whole row moves
+		source.IdWarehouse = t.ToWarehouse
+		source.Version++
+		ps[source.Id] = source
+	case !ok:
+		// - split the row, the new one keeps the attributes of the source
+		split := source
+		split.Id, err = nextProductId(r.stProd, ps)
+		if err != nil {
+			return
+		}
+		split.Quantity = t.Quantity
+		split.IdWarehouse = t.ToWarehouse
+		split.Version = 1
+		ps[split.Id] = split
+
+		source.Quantity -= t.Quantity
+		source.Version++
+		ps[source.Id] = source
+	default:
+		// - merge into the destination row
+		target.Quantity += t.Quantity
+		target.Version++
+		ps[target.Id] = target
+
+		if t.Quantity == source.Quantity {
+			delete(ps, source.Id)
+		} else {
+			source.Quantity -= t.Quantity
+			source.Version++
+			ps[source.Id] = source
+		}
+	}
+
+	// write all products
+	err = r.stProd.WriteAll(ps)
+	if err != nil {
+		return
+	}
+
+	// record the transfer
+	ts, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+	var maxId int
+	for k := range ts {
+		maxId = max(maxId, k)
+	}
+	t.Id = maxId + 1
+	t.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	ts[t.Id] = *t
+
+	// write all transfers
+	err = r.st.WriteAll(ts)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// FindByWarehouse finds the transfers from or to a warehouse, newest first.
+func (r *RepositoryTransferStore) FindByWarehouse(id int) (t []internal.Transfer, err error) {
+	// read all transfers
+	ts, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// find transfers
+	for _, v := range ts {
+		if v.FromWarehouse == id || v.ToWarehouse == id {
+			t = append(t, v)
+		}
+	}
+	slices.SortFunc(t, func(a, b internal.Transfer) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Id, a.Id)
+	})
+
+	return
+}
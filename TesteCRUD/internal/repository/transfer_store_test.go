@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"app/internal"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryTransferStore_Save(t *testing.T) {
+	t.Run("success - split row", func(t *testing.T) {
+		_, rp, stProd := newRepositoriesStore(t)
+		tr := internal.Transfer{CodeValue: "A", Quantity: 10, FromWarehouse: 1, ToWarehouse: 2}
+
+		err := rp.Save(&tr)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, tr.Id)
+		ps, err := stProd.ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, ps, 3)
+		assert.Equal(t, 20, ps[1].Quantity)
+		assert.Equal(t, 10, ps[3].Quantity)
+		assert.Equal(t, 2, ps[3].IdWarehouse)
+		transfers, err := rp.FindByWarehouse(2)
+		assert.NoError(t, err)
+		assert.Equal(t, []internal.Transfer{tr}, transfers)
+	})
+
+	t.Run("success - whole row merged", func(t *testing.T) {
+		_, rp, stProd := newRepositoriesStore(t)
+		first := internal.Transfer{CodeValue: "A", Quantity: 10, FromWarehouse: 1, ToWarehouse: 2}
+		assert.NoError(t, rp.Save(&first))
+
+		err := rp.Save(&internal.Transfer{CodeValue: "A", Quantity: 20, FromWarehouse: 1, ToWarehouse: 2})
+
+		assert.NoError(t, err)
+		ps, err := stProd.ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, ps, 2)
+		assert.Equal(t, 30, ps[3].Quantity)
+	})
+
+	t.Run("error - destination full", func(t *testing.T) {
+		rpWare, rp, _ := newRepositoriesStore(t)
+		assert.NoError(t, rpWare.Update(&internal.Warehouse{Id: 2, Name: "Second Warehouse", Capacity: 39}))
+
+		err := rp.Save(&internal.Transfer{CodeValue: "A", Quantity: 30, FromWarehouse: 1, ToWarehouse: 2})
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductWarehouseFull)
+	})
+
+	t.Run("error - destination not found", func(t *testing.T) {
+		_, rp, _ := newRepositoriesStore(t)
+
+		err := rp.Save(&internal.Transfer{CodeValue: "A", Quantity: 10, FromWarehouse: 1, ToWarehouse: 3})
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryTransferDestinationInvalid)
+	})
+
+	t.Run("error - quantity above the stock", func(t *testing.T) {
+		_, rp, _ := newRepositoriesStore(t)
+
+		err := rp.Save(&internal.Transfer{CodeValue: "A", Quantity: 31, FromWarehouse: 1, ToWarehouse: 2})
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryTransferQuantityInvalid)
+	})
+}
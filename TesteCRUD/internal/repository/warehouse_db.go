@@ -4,6 +4,7 @@ import (
 	"app/internal"
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
@@ -48,7 +49,7 @@ func (r *RepositoryWarehouseDB) FindById(id int) (w internal.Warehouse, err erro
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return w, fmt.Errorf("warehouse not found with id: %d", id)
+			return w, fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, id)
 		}
 		return w, err
 	}
@@ -57,23 +58,200 @@ func (r *RepositoryWarehouseDB) FindById(id int) (w internal.Warehouse, err erro
 }
 
 func (r *RepositoryWarehouseDB) Save(w *internal.Warehouse) (err error) {
-	var lastID int
+	// the id is assigned by AUTO_INCREMENT, so concurrent saves never share one
+	insertQuery := "INSERT INTO warehouses (name, address, telephone, capacity) VALUES (?, ?, ?, ?)"
 
-	query := "SELECT COALESCE(MAX(id), 0) FROM warehouses;"
-	err = r.db.QueryRow(query).Scan(&lastID)
+	res, err := r.db.Exec(insertQuery,
+		w.Name,
+		w.Address,
+		w.Telephone,
+		w.Capacity)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	w.Id = int(id)
+	return nil
+}
+
+func (r *RepositoryWarehouseDB) Update(w *internal.Warehouse) (err error) {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	w.Id = lastID + 1
+	// lock the warehouse, so no product is written to it meanwhile
+	var id int
+	err = tx.QueryRow("SELECT id FROM warehouses WHERE id = ? FOR UPDATE", w.Id).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, w.Id)
+		}
+		return err
+	}
 
-	insertQuery := "INSERT INTO warehouses (id, name, address, telephone, capacity) VALUES (?, ?, ?, ?, ?)"
+	// the products must still fit
+	var used int
+	err = tx.QueryRow("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?", w.Id).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if w.Capacity < used {
+		return fmt.Errorf("%w: %d stored", internal.ErrRepositoryWarehouseCapacityTooLow, used)
+	}
 
-	_, err = r.db.Exec(insertQuery,
-		w.Id,
+	query := "UPDATE warehouses SET name = ?, address = ?, telephone = ?, capacity = ? WHERE id = ?"
+	_, err = tx.Exec(query,
 		w.Name,
 		w.Address,
 		w.Telephone,
-		w.Capacity)
-	return err
+		w.Capacity,
+		w.Id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RepositoryWarehouseDB) Delete(id int, reassignTo *int) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// lock the warehouse, so no product is written to it meanwhile
+	var lockedId int
+	err = tx.QueryRow("SELECT id FROM warehouses WHERE id = ? FOR UPDATE", id).Scan(&lockedId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, id)
+		}
+		return err
+	}
+
+	// move the products
+	var count, quantity int
+	err = tx.QueryRow("SELECT COUNT(*), COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?", id).Scan(&count, &quantity)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		if reassignTo == nil {
+			return fmt.Errorf("%w: %d products", internal.ErrRepositoryWarehouseInUse, count)
+		}
+		if *reassignTo == id {
+			return fmt.Errorf("%w: cannot reassign to the deleted warehouse", internal.ErrRepositoryWarehouseReassignInvalid)
+		}
+
+		// - the products must fit in the other warehouse
+		var capacity int
+		err = tx.QueryRow("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE", *reassignTo).Scan(&capacity)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: warehouse %d not found", internal.ErrRepositoryWarehouseReassignInvalid, *reassignTo)
+			}
+			return err
+		}
+		var used int
+		err = tx.QueryRow("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?", *reassignTo).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used+quantity > capacity {
+			return &internal.WarehouseCapacityError{IdWarehouse: *reassignTo, Capacity: capacity, Used: used}
+		}
+
+		_, err = tx.Exec("UPDATE products SET id_warehouse = ?, version = version + 1 WHERE id_warehouse = ?", *reassignTo, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("DELETE FROM warehouses WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// queryWarehouseReport aggregates the products of each warehouse.
+// The parameters are today (expired), today and soon (expiring soon).
+const queryWarehouseReport = `
+	SELECT
+		w.id, w.name, w.address, w.telephone, w.capacity,
+		COUNT(p.id),
+		COALESCE(SUM(p.quantity), 0),
+		COALESCE(SUM(p.quantity * p.price), 0),
+		COALESCE(SUM(p.expiration < ?), 0),
+		COALESCE(SUM(p.expiration >= ? AND p.expiration <= ?), 0),
+		COALESCE(SUM(p.is_published = '1'), 0)
+	FROM warehouses w
+	LEFT JOIN products p ON p.id_warehouse = w.id`
+
+func (r *RepositoryWarehouseDB) Report(id int, today, soon time.Time) (wr internal.WarehouseReport, err error) {
+	query := queryWarehouseReport + " WHERE w.id = ? GROUP BY w.id, w.name, w.address, w.telephone, w.capacity"
+	rows, err := r.db.Query(query, today.Format(time.DateOnly), today.Format(time.DateOnly), soon.Format(time.DateOnly), id)
+	if err != nil {
+		return wr, err
+	}
+	defer rows.Close()
+
+	reports, err := scanWarehouseReports(rows)
+	if err != nil {
+		return wr, err
+	}
+	if len(reports) == 0 {
+		return wr, fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, id)
+	}
+
+	return reports[0], nil
+}
+
+func (r *RepositoryWarehouseDB) ReportAll(today, soon time.Time) ([]internal.WarehouseReport, error) {
+	query := queryWarehouseReport + " GROUP BY w.id, w.name, w.address, w.telephone, w.capacity ORDER BY w.id"
+	rows, err := r.db.Query(query, today.Format(time.DateOnly), today.Format(time.DateOnly), soon.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWarehouseReports(rows)
+}
+
+// scanWarehouseReports reads every row of a warehouse report query.
+func scanWarehouseReports(rows *sql.Rows) ([]internal.WarehouseReport, error) {
+	var reports []internal.WarehouseReport
+	for rows.Next() {
+		var wr internal.WarehouseReport
+		if err := rows.Scan(&wr.Id, &wr.Name, &wr.Address, &wr.Telephone, &wr.Capacity,
+			&wr.Products, &wr.Units, &wr.StockValue, &wr.Expired, &wr.ExpiringSoon, &wr.Published); err != nil {
+			return nil, err
+		}
+
+		completeWarehouseReport(&wr)
+
+		reports = append(reports, wr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
 }
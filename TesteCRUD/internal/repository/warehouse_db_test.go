@@ -3,7 +3,9 @@ package repository_test
 import (
 	"app/internal"
 	"app/internal/repository"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -64,12 +66,9 @@ func TestWarehouseRepository_Create(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(id\\), 0\\) FROM warehouses").
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(0))
-
 	mock.ExpectExec("INSERT INTO warehouses").
-		WithArgs(1, "New Warehouse", "123 Test St", "1234567", 150).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WithArgs("New Warehouse", "123 Test St", "1234567", 150).
+		WillReturnResult(sqlmock.NewResult(7, 1))
 
 	repo := repository.NewRepositoryWarehouseDB(db)
 
@@ -82,6 +81,158 @@ func TestWarehouseRepository_Create(t *testing.T) {
 
 	err = repo.Save(&wh)
 	assert.NoError(t, err)
+	assert.Equal(t, 7, wh.Id)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestWarehouseRepository_Update(t *testing.T) {
+	t.Run("error - capacity below the quantity of products", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(80))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryWarehouseDB(db)
+		wh := internal.Warehouse{Id: 1, Name: "Main Warehouse", Capacity: 50}
+		err = repo.Update(&wh)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseCapacityTooLow)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestWarehouseRepository_Delete(t *testing.T) {
+	t.Run("error - warehouse has products", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "sum"}).AddRow(2, 30))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryWarehouseDB(db)
+		err = repo.Delete(1, nil)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseInUse)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - products reassigned", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "sum"}).AddRow(2, 30))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(70))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE products SET id_warehouse = ?, version = version + 1 WHERE id_warehouse = ?")).
+			WithArgs(2, 1).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM warehouses WHERE id = ?")).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := repository.NewRepositoryWarehouseDB(db)
+		to := 2
+		err = repo.Delete(1, &to)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - products do not fit in the other warehouse", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "sum"}).AddRow(2, 30))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT capacity FROM warehouses WHERE id = ? FOR UPDATE")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(100))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) FROM products WHERE id_warehouse = ?")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(90))
+		mock.ExpectRollback()
+
+		repo := repository.NewRepositoryWarehouseDB(db)
+		to := 2
+		err = repo.Delete(1, &to)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductWarehouseFull)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestWarehouseRepository_Report(t *testing.T) {
+	today := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	soon := today.AddDate(0, 0, 30)
+	columns := []string{"id", "name", "address", "telephone", "capacity", "products", "units", "stock_value", "expired", "expiring_soon", "published"}
+
+	t.Run("success - warehouse reported", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta("FROM warehouses w LEFT JOIN products p ON p.id_warehouse = w.id WHERE w.id = ?")).
+			WithArgs("2022-06-01", "2022-06-01", "2022-07-01", 1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Main Warehouse", "221 Baker Street", "4555666", 1000, 3, 250, 1234.567, 1, 1, 2))
+
+		repo := repository.NewRepositoryWarehouseDB(db)
+		report, err := repo.Report(1, today, soon)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 250, report.Units)
+		assert.Equal(t, 1234.57, report.StockValue)
+		assert.Equal(t, 25.0, report.Utilization)
+		assert.Equal(t, 1, report.Unpublished)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - warehouse not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE w.id = ?")).
+			WithArgs("2022-06-01", "2022-06-01", "2022-07-01", 9).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		repo := repository.NewRepositoryWarehouseDB(db)
+		_, err = repo.Report(9, today, soon)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"app/internal"
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// NewRepositoryWarehouseStore creates a new repository for warehouses.
+// The product and transfer repositories on stProd share its lock when they are given this repository.
+func NewRepositoryWarehouseStore(st internal.StoreWarehouse, stProd internal.StoreProduct) (r *RepositoryWarehouseStore) {
+	r = &RepositoryWarehouseStore{
+		st:     st,
+		stProd: stProd,
+		mu:     &sync.Mutex{},
+	}
+	return
+}
+
+// RepositoryWarehouseStore is a repository for warehouses.
+type RepositoryWarehouseStore struct {
+	// st is the underlying store.
+	st internal.StoreWarehouse
+	// stProd is the store of the products of the warehouses.
+	stProd internal.StoreProduct
+	// mu serializes the writes of the warehouses and of their products,
+	// so the capacity of a warehouse is checked against the products written.
+	mu *sync.Mutex
+}
+
+// FindAll finds all warehouses, sorted by id.
+func (r *RepositoryWarehouseStore) FindAll() (w []internal.Warehouse, err error) {
+	// read all warehouses
+	ws, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// sort warehouses
+	for _, v := range ws {
+		w = append(w, v)
+	}
+	slices.SortFunc(w, func(a, b internal.Warehouse) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	return
+}
+
+// FindById finds a warehouse by id.
+func (r *RepositoryWarehouseStore) FindById(id int) (w internal.Warehouse, err error) {
+	// read all warehouses
+	ws, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// find warehouse
+	w, ok := ws[id]
+	if !ok {
+		err = fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, id)
+		return
+	}
+
+	return
+}
+
+// Save saves a warehouse.
+func (r *RepositoryWarehouseStore) Save(w *internal.Warehouse) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// read all warehouses
+	ws, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// set id from the sequence of the store, so the id of a deleted warehouse is not reused
+	// and the transfers of the deleted one are not taken for the new one's.
+	// Warehouses added to the store by other means may already hold ids of the sequence, those are skipped.
+	for {
+		(*w).Id, err = r.st.NextId()
+		if err != nil {
+			return
+		}
+		if _, ok := ws[w.Id]; !ok {
+			break
+		}
+	}
+
+	// add warehouse
+	ws[w.Id] = *w
+
+	// write all warehouses
+	err = r.st.WriteAll(ws)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Update updates a warehouse, its products must still fit in it.
+func (r *RepositoryWarehouseStore) Update(w *internal.Warehouse) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// read all warehouses
+	ws, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+	if _, ok := ws[w.Id]; !ok {
+		err = fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, w.Id)
+		return
+	}
+
+	// the products must still fit
+	ps, err := r.stProd.ReadAll()
+	if err != nil {
+		return
+	}
+	var used int
+	for _, v := range ps {
+		if v.IdWarehouse == w.Id {
+			used += v.Quantity
+		}
+	}
+	if w.Capacity < used {
+		err = fmt.Errorf("%w: %d stored", internal.ErrRepositoryWarehouseCapacityTooLow, used)
+		return
+	}
+
+	// update warehouse
+	ws[w.Id] = *w
+
+	// write all warehouses
+	err = r.st.WriteAll(ws)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Delete deletes a warehouse, moving its products to reassignTo.
+func (r *RepositoryWarehouseStore) Delete(id int, reassignTo *int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// read all warehouses
+	ws, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+	if _, ok := ws[id]; !ok {
+		err = fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, id)
+		return
+	}
+
+	// move the products
+	ps, err := r.stProd.ReadAll()
+	if err != nil {
+		return
+	}
+	var count, quantity int
+	for _, v := range ps {
+		if v.IdWarehouse == id {
+			count++
+			quantity += v.Quantity
+		}
+	}
+	if count > 0 {
+		if reassignTo == nil {
+			err = fmt.Errorf("%w: %d products", internal.ErrRepositoryWarehouseInUse, count)
+			return
+		}
+		if *reassignTo == id {
+			err = fmt.Errorf("%w: cannot reassign to the deleted warehouse", internal.ErrRepositoryWarehouseReassignInvalid)
+			return
+		}
+
+		// - the products must fit in the other warehouse
+		wh, ok := ws[*reassignTo]
+		if !ok {
+			err = fmt.Errorf("%w: warehouse %d not found", internal.ErrRepositoryWarehouseReassignInvalid, *reassignTo)
+			return
+		}
+		var used int
+		codes := make(map[string]bool)
+		for _, v := range ps {
+			if v.IdWarehouse == wh.Id {
+				used += v.Quantity
+				codes[v.CodeValue] = true
+			}
+		}
+		if used+quantity > wh.Capacity {
+			err = &internal.WarehouseCapacityError{IdWarehouse: wh.Id, Capacity: wh.Capacity, Used: used}
+			return
+		}
+
+		// - the code values must stay unique in the other warehouse
+		for k, v := range ps {
+			if v.IdWarehouse != id {
+				continue
+			}
+			if codes[v.CodeValue] {
+				err = fmt.Errorf("%w: warehouse %d already has code value %s", internal.ErrRepositoryWarehouseReassignInvalid, wh.Id, v.CodeValue)
+				return
+			}
+			v.IdWarehouse = wh.Id
+			v.Version++
+			ps[k] = v
+		}
+
+		// - write all products, before the warehouse is deleted so no product is left without one
+		err = r.stProd.WriteAll(ps)
+		if err != nil {
+			return
+		}
+	}
+
+	// delete warehouse
+	delete(ws, id)
+
+	// write all warehouses
+	err = r.st.WriteAll(ws)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Report returns the stock report of a warehouse.
+func (r *RepositoryWarehouseStore) Report(id int, today, soon time.Time) (wr internal.WarehouseReport, err error) {
+	reports, err := r.report(today, soon, func(w internal.Warehouse) bool { return w.Id == id })
+	if err != nil {
+		return
+	}
+	if len(reports) == 0 {
+		err = fmt.Errorf("%w: id %d", internal.ErrRepositoryWarehouseNotFound, id)
+		return
+	}
+
+	wr = reports[0]
+	return
+}
+
+// ReportAll returns the stock report of every warehouse, sorted by id.
+func (r *RepositoryWarehouseStore) ReportAll(today, soon time.Time) (wr []internal.WarehouseReport, err error) {
+	return r.report(today, soon, func(w internal.Warehouse) bool { return true })
+}
+
+// report aggregates the products of each warehouse matching fn, sorted by id.
+func (r *RepositoryWarehouseStore) report(today, soon time.Time, fn func(w internal.Warehouse) bool) (wr []internal.WarehouseReport, err error) {
+	// read all warehouses and products
+	ws, err := r.FindAll()
+	if err != nil {
+		return
+	}
+	ps, err := r.stProd.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// aggregate products
+	ix := make(map[int]int)
+	for _, w := range ws {
+		if fn(w) {
+			ix[w.Id] = len(wr)
+			wr = append(wr, internal.WarehouseReport{Warehouse: w})
+		}
+	}
+	for _, p := range ps {
+		i, ok := ix[p.IdWarehouse]
+		if !ok {
+			continue
+		}
+
+		wr[i].Products++
+		wr[i].Units += p.Quantity
+		wr[i].StockValue += float64(p.Quantity) * p.Price
+		switch {
+		case p.Expiration.Before(today):
+			wr[i].Expired++
+		case !p.Expiration.After(soon):
+			wr[i].ExpiringSoon++
+		}
+		if p.IsPublished {
+			wr[i].Published++
+		}
+	}
+	for i := range wr {
+		completeWarehouseReport(&wr[i])
+	}
+
+	return
+}
+
+// completeWarehouseReport derives the fields of a report from the aggregates of its products.
+func completeWarehouseReport(wr *internal.WarehouseReport) {
+	wr.Unpublished = wr.Products - wr.Published
+	wr.StockValue = math.Round(wr.StockValue*100) / 100
+	if wr.Capacity > 0 {
+		wr.Utilization = math.Round(float64(wr.Units)/float64(wr.Capacity)*10000) / 100
+	}
+}
@@ -0,0 +1,132 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRepositoriesStore returns the repositories on stores in memory with two warehouses,
+// the first with a product of code value "A" and the second with a product of code value "B".
+func newRepositoriesStore(t *testing.T) (rpWare *repository.RepositoryWarehouseStore, rpTransfer *repository.RepositoryTransferStore, stProd *store.StoreMemory[internal.Product]) {
+	stWare := store.NewStoreMemory[internal.Warehouse]()
+	err := stWare.WriteAll(map[int]internal.Warehouse{
+		1: {Id: 1, Name: "Main Warehouse", Capacity: 100},
+		2: {Id: 2, Name: "Second Warehouse", Capacity: 50},
+	})
+	assert.NoError(t, err)
+
+	stProd = store.NewStoreMemory[internal.Product]()
+	exp := time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC)
+	err = stProd.WriteAll(map[int]internal.Product{
+		1: {Id: 1, ProductAttributes: internal.ProductAttributes{CodeValue: "A", Quantity: 30, Price: 2, IsPublished: true, Expiration: exp}, IdWarehouse: 1, Version: 1},
+		2: {Id: 2, ProductAttributes: internal.ProductAttributes{CodeValue: "B", Quantity: 10, Price: 1, Expiration: exp}, IdWarehouse: 2, Version: 1},
+	})
+	assert.NoError(t, err)
+
+	rpWare = repository.NewRepositoryWarehouseStore(stWare, stProd)
+	rpTransfer = repository.NewRepositoryTransferStore(store.NewStoreMemory[internal.Transfer](), stProd, rpWare)
+	return
+}
+
+func TestRepositoryWarehouseStore_Save(t *testing.T) {
+	t.Run("success - id of a deleted warehouse not reused", func(t *testing.T) {
+		rp, _, _ := newRepositoriesStore(t)
+		to := 1
+		assert.NoError(t, rp.Delete(2, &to))
+		w := internal.Warehouse{Name: "Third Warehouse", Capacity: 10}
+
+		err := rp.Save(&w)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, w.Id)
+		_, err = rp.FindById(2)
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseNotFound)
+	})
+}
+
+func TestRepositoryWarehouseStore_Update(t *testing.T) {
+	t.Run("success - capacity above the products", func(t *testing.T) {
+		rp, _, _ := newRepositoriesStore(t)
+
+		err := rp.Update(&internal.Warehouse{Id: 1, Name: "Main Warehouse", Capacity: 30})
+
+		assert.NoError(t, err)
+		w, err := rp.FindById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, 30, w.Capacity)
+	})
+
+	t.Run("error - capacity below the products", func(t *testing.T) {
+		rp, _, _ := newRepositoriesStore(t)
+
+		err := rp.Update(&internal.Warehouse{Id: 1, Name: "Main Warehouse", Capacity: 29})
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseCapacityTooLow)
+	})
+
+	t.Run("error - warehouse not found", func(t *testing.T) {
+		rp, _, _ := newRepositoriesStore(t)
+
+		err := rp.Update(&internal.Warehouse{Id: 3, Capacity: 10})
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseNotFound)
+	})
+}
+
+func TestRepositoryWarehouseStore_Delete(t *testing.T) {
+	t.Run("success - products reassigned", func(t *testing.T) {
+		rp, _, stProd := newRepositoriesStore(t)
+		to := 1
+
+		err := rp.Delete(2, &to)
+
+		assert.NoError(t, err)
+		_, err = rp.FindById(2)
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseNotFound)
+		ps, err := stProd.ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, ps[2].IdWarehouse)
+		assert.Equal(t, 2, ps[2].Version)
+	})
+
+	t.Run("error - warehouse in use", func(t *testing.T) {
+		rp, _, _ := newRepositoriesStore(t)
+
+		err := rp.Delete(2, nil)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryWarehouseInUse)
+	})
+
+	t.Run("error - products do not fit", func(t *testing.T) {
+		rp, _, _ := newRepositoriesStore(t)
+		assert.NoError(t, rp.Update(&internal.Warehouse{Id: 2, Name: "Second Warehouse", Capacity: 39}))
+		to := 2
+
+		err := rp.Delete(1, &to)
+
+		assert.ErrorIs(t, err, internal.ErrRepositoryProductWarehouseFull)
+	})
+}
+
+func TestRepositoryWarehouseStore_ReportAll(t *testing.T) {
+	rp, _, _ := newRepositoriesStore(t)
+	today := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	reports, err := rp.ReportAll(today, today.AddDate(0, 0, 7))
+
+	assert.NoError(t, err)
+	assert.Len(t, reports, 2)
+	assert.Equal(t, 1, reports[0].Id)
+	assert.Equal(t, 1, reports[0].Products)
+	assert.Equal(t, 30, reports[0].Units)
+	assert.Equal(t, 60.0, reports[0].StockValue)
+	assert.Equal(t, 30.0, reports[0].Utilization)
+	assert.Equal(t, 1, reports[0].ExpiringSoon)
+	assert.Equal(t, 1, reports[0].Published)
+	assert.Equal(t, 1, reports[1].Unpublished)
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// fileState identifies the content of a file at some point.
+type fileState struct {
+	// exists is whether the file exists.
+	exists bool
+	// hash is the SHA-256 of the content of the file.
+	hash [sha256.Size]byte
+}
+
+// changedFrom reports whether the file has other content than it had at st.
+// The content is compared by hash, since the modification time may not change on fast or coarse writes.
+func (s fileState) changedFrom(st fileState) bool {
+	return s.exists != st.exists || s.hash != st.hash
+}
+
+// readFile reads the content and the state of a file, a missing file has no content.
+func readFile(path string) (b []byte, st fileState, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	defer f.Close()
+
+	b, err = io.ReadAll(f)
+	if err != nil {
+		return
+	}
+
+	st = fileState{exists: true, hash: sha256.Sum256(b)}
+	return
+}
+
+// writeFileAtomic replaces the content of a file, so a crash leaves either the old or the new content.
+// The content goes to a temporary file of the same directory, which is synced and renamed over the file.
+func writeFileAtomic(path string, b []byte) (err error) {
+	dir := filepath.Dir(path)
+
+	// temporary file
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	// write and sync
+	if _, err = f.Write(b); err != nil {
+		return
+	}
+	if err = f.Chmod(0644); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	// replace
+	if err = os.Rename(f.Name(), path); err != nil {
+		return
+	}
+
+	// sync the directory, so the rename survives a crash
+	d, errDir := os.Open(dir)
+	if errDir == nil {
+		d.Sync()
+		d.Close()
+	}
+	return
+}
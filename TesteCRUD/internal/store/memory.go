@@ -0,0 +1,62 @@
+package store
+
+import (
+	"maps"
+	"sync"
+)
+
+// NewStoreMemory creates a new empty store in memory.
+func NewStoreMemory[T any]() (s *StoreMemory[T]) {
+	s = &StoreMemory[T]{
+		v: make(map[int]T),
+	}
+	return
+}
+
+// StoreMemory is a store that keeps its values in memory, they are lost when the application stops.
+// It is a store for products, warehouses or transfers, and is meant for local development and tests.
+type StoreMemory[T any] struct {
+	// mu guards the fields below.
+	mu sync.Mutex
+	// v are the values by id.
+	v map[int]T
+	// id is the last id assigned or written.
+	id int
+}
+
+// ReadAll reads all values from the store.
+func (s *StoreMemory[T]) ReadAll() (v map[int]T, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// callers change the map they get, so they get a copy
+	v = maps.Clone(s.v)
+	return
+}
+
+// WriteAll writes all values to the store.
+func (s *StoreMemory[T]) WriteAll(v map[int]T) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.v = maps.Clone(v)
+	if s.v == nil {
+		s.v = make(map[int]T)
+	}
+
+	// the ids written are taken, also once their value is deleted
+	for k := range s.v {
+		s.id = max(s.id, k)
+	}
+	return
+}
+
+// NextId assigns the next id, ids are not reused after their value is deleted.
+func (s *StoreMemory[T]) NextId() (id int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.id++
+	id = s.id
+	return
+}
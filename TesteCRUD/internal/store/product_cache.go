@@ -0,0 +1,188 @@
+package store
+
+import (
+	"app/internal"
+	"errors"
+	"log"
+	"maps"
+	"sync"
+	"time"
+)
+
+// NewStoreProductCache creates a new store that caches the products of st in memory.
+// With a zero flushInterval every write reaches st before returning, otherwise the writes are
+// written behind: the first write after a flush schedules the next one flushInterval later,
+// and the writes meanwhile are flushed together.
+func NewStoreProductCache(st internal.StoreProduct, flushInterval time.Duration) (s *StoreProductCache) {
+	s = &StoreProductCache{
+		st:            st,
+		flushInterval: flushInterval,
+	}
+	return
+}
+
+// StoreProductCache is a store that loads the products of another store once and serves the reads from memory.
+//
+// A write rejected by the underlying store is undone in the cache, and when the store was modified
+// by someone else the cache is dropped, so the next read loads the modified products.
+// Written behind, a write is accepted before it reaches the store: a failed flush is logged and retried
+// after the interval, and until a flush succeeds the writes are written through so their callers get the error.
+// A flush rejected because the store was modified by someone else keeps the pending writes, as their callers
+// were already answered, and is not retried so the modification is not overwritten: the next writes and Flush
+// return the conflict instead.
+type StoreProductCache struct {
+	// st is the underlying store.
+	st internal.StoreProduct
+	// flushInterval is the delay of a write behind, zero writes through.
+	flushInterval time.Duration
+	// mu guards the fields below.
+	mu sync.Mutex
+	// p are the cached products, nil until loaded.
+	p map[int]internal.Product
+	// dirty is whether p has writes not flushed to st.
+	dirty bool
+	// timer is the scheduled flush, nil when none.
+	timer *time.Timer
+	// err is the error of the last scheduled flush, nil once a flush succeeds.
+	err error
+}
+
+// ReadAll reads all products from the cache, loading them from the underlying store the first time.
+func (s *StoreProductCache) ReadAll() (p map[int]internal.Product, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// load
+	if s.p == nil {
+		s.p, err = s.st.ReadAll()
+		if err != nil {
+			s.p = nil
+			return
+		}
+	}
+
+	// callers change the map they get, so they get a copy
+	p = maps.Clone(s.p)
+	return
+}
+
+// WriteAll writes all products to the cache, and to the underlying store now or after the flush interval.
+func (s *StoreProductCache) WriteAll(p map[int]internal.Product) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conflicted() {
+		err = s.err
+		return
+	}
+
+	prev, prevDirty := s.p, s.dirty
+	s.p = maps.Clone(p)
+	s.dirty = true
+
+	// write through, also while the scheduled flushes fail
+	if s.flushInterval == 0 || s.err != nil {
+		err = s.flush()
+		if err != nil {
+			// undo the write
+			s.p, s.dirty = prev, prevDirty
+			s.conflict(err)
+		}
+		return
+	}
+
+	// write behind
+	s.schedule()
+	return
+}
+
+// schedule schedules a flush after the interval, unless one is already scheduled.
+// It must be called with mu held.
+func (s *StoreProductCache) schedule() {
+	if s.timer != nil {
+		return
+	}
+	s.timer = time.AfterFunc(s.flushInterval, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.timer = nil
+		s.err = s.flush()
+		if s.err == nil {
+			return
+		}
+		log.Println("flush product store:", s.err)
+		s.conflict(s.err)
+		if !s.conflicted() {
+			s.schedule()
+		}
+	})
+}
+
+// conflict handles err when it is because the underlying store was modified by someone else.
+// Without pending writes the cache is dropped, so the next read loads the modified products.
+// Pending writes are kept and err with them, see conflicted. It must be called with mu held.
+func (s *StoreProductCache) conflict(err error) {
+	if !errors.Is(err, internal.ErrStoreProductModified) {
+		return
+	}
+	if s.dirty {
+		log.Println("flush product store: pending writes kept, products modified externally")
+		s.err = err
+		return
+	}
+	s.p = nil
+}
+
+// conflicted reports whether the pending writes conflict with a modification of the underlying store.
+// Writing them would overwrite the modification, so they are not written and the conflict is returned instead.
+// It must be called with mu held.
+func (s *StoreProductCache) conflicted() bool {
+	return s.dirty && errors.Is(s.err, internal.ErrStoreProductModified)
+}
+
+// NextId assigns the next product id from the underlying store, whose sequence is already persisted.
+func (s *StoreProductCache) NextId() (id int, err error) {
+	return s.st.NextId()
+}
+
+// Flush writes the pending writes to the underlying store, and returns the error of the last
+// scheduled flush when they are already written, or the conflict that keeps them from being written.
+// It is called on shutdown so no write is lost without being reported.
+func (s *StoreProductCache) Flush() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.conflicted() {
+		err = s.err
+		return
+	}
+	if !s.dirty {
+		err, s.err = s.err, nil
+		return
+	}
+	err = s.flush()
+	s.conflict(err)
+	return
+}
+
+// flush writes the cached products to the underlying store, it must be called with mu held.
+// On error the writes stay pending.
+func (s *StoreProductCache) flush() (err error) {
+	if !s.dirty {
+		return
+	}
+
+	err = s.st.WriteAll(s.p)
+	if err != nil {
+		return
+	}
+
+	s.dirty = false
+	s.err = nil
+	return
+}
@@ -0,0 +1,253 @@
+package store_test
+
+import (
+	"app/internal"
+	"app/internal/store"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// storeProductCounter is a store in memory that counts its reads and writes.
+type storeProductCounter struct {
+	p      map[int]internal.Product
+	reads  int
+	writes int
+	err    error
+}
+
+func (s *storeProductCounter) ReadAll() (map[int]internal.Product, error) {
+	s.reads++
+	p := make(map[int]internal.Product, len(s.p))
+	for k, v := range s.p {
+		p[k] = v
+	}
+	return p, nil
+}
+
+func (s *storeProductCounter) WriteAll(p map[int]internal.Product) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.writes++
+	s.p = p
+	return nil
+}
+
+func (s *storeProductCounter) NextId() (int, error) {
+	return len(s.p) + 1, nil
+}
+
+// Tests for StoreProductCache.ReadAll
+func TestStoreProductCache_ReadAll(t *testing.T) {
+	t.Run("success - loaded once", func(t *testing.T) {
+		// arrange
+		st := &storeProductCounter{p: map[int]internal.Product{1: {Id: 1}}}
+		ch := store.NewStoreProductCache(st, 0)
+
+		// act
+		ps, err := ch.ReadAll()
+		require.NoError(t, err)
+		delete(ps, 1)
+		ps, err = ch.ReadAll()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+		require.Equal(t, 1, st.reads)
+	})
+}
+
+// Tests for StoreProductCache.WriteAll
+func TestStoreProductCache_WriteAll(t *testing.T) {
+	t.Run("success - written through", func(t *testing.T) {
+		// arrange
+		st := &storeProductCounter{p: map[int]internal.Product{}}
+		ch := store.NewStoreProductCache(st, 0)
+
+		// act
+		err := ch.WriteAll(map[int]internal.Product{1: {Id: 1}})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, st.writes)
+		require.Len(t, st.p, 1)
+	})
+
+	t.Run("success - written behind until flushed", func(t *testing.T) {
+		// arrange
+		st := &storeProductCounter{p: map[int]internal.Product{}}
+		ch := store.NewStoreProductCache(st, time.Hour)
+
+		// act
+		err1 := ch.WriteAll(map[int]internal.Product{1: {Id: 1}})
+		err2 := ch.WriteAll(map[int]internal.Product{1: {Id: 1}, 2: {Id: 2}})
+		ps, err3 := ch.ReadAll()
+		writes := st.writes
+		errFlush := ch.Flush()
+
+		// assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.NoError(t, err3)
+		require.Len(t, ps, 2)
+		require.Equal(t, 0, writes)
+		require.NoError(t, errFlush)
+		require.Equal(t, 1, st.writes)
+		require.Len(t, st.p, 2)
+	})
+
+	t.Run("success - written behind after the interval", func(t *testing.T) {
+		// arrange
+		st := &storeProductCounter{p: map[int]internal.Product{}}
+		ch := store.NewStoreProductCache(st, 10*time.Millisecond)
+
+		// act
+		err := ch.WriteAll(map[int]internal.Product{1: {Id: 1}})
+
+		// assert
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			// Flush takes the lock, so the write is read after the scheduled flush is done
+			return ch.Flush() == nil && st.writes == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("error - failed flush kept pending", func(t *testing.T) {
+		// arrange
+		st := &storeProductCounter{p: map[int]internal.Product{}, err: errors.New("disk full")}
+		ch := store.NewStoreProductCache(st, time.Hour)
+		err := ch.WriteAll(map[int]internal.Product{1: {Id: 1}})
+		require.NoError(t, err)
+
+		// act
+		errFlush := ch.Flush()
+		st.err = nil
+		errRetry := ch.Flush()
+
+		// assert
+		require.Error(t, errFlush)
+		require.NoError(t, errRetry)
+		require.Equal(t, 1, st.writes)
+	})
+}
+
+// Tests for StoreProductCache on a StoreProductJSON modified by someone else
+func TestStoreProductCache_ModifiedExternally(t *testing.T) {
+	t.Run("error - written through, undone and reloaded", func(t *testing.T) {
+		// arrange
+		st, path := newStoreProductJSON(t)
+		ch := store.NewStoreProductCache(st, 0)
+		ps, err := ch.ReadAll()
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(`[]`), 0644)
+		require.NoError(t, err)
+
+		// act
+		delete(ps, 1)
+		ps[2] = internal.Product{Id: 2, ProductAttributes: internal.ProductAttributes{Expiration: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, IdWarehouse: 1, Version: 1}
+		err = ch.WriteAll(ps)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrStoreProductModified)
+		ps, err = ch.ReadAll()
+		require.NoError(t, err)
+		require.Empty(t, ps)
+		// - the write is accepted again once based on the modified products
+		ps[3] = internal.Product{Id: 3, ProductAttributes: internal.ProductAttributes{Expiration: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, IdWarehouse: 1, Version: 1}
+		err = ch.WriteAll(ps)
+		require.NoError(t, err)
+		ps, err = st.ReadAll()
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+		require.Contains(t, ps, 3)
+	})
+
+	t.Run("error - written behind, pending writes kept and the conflict reported", func(t *testing.T) {
+		// arrange
+		st, path := newStoreProductJSON(t)
+		ch := store.NewStoreProductCache(st, time.Hour)
+		ps, err := ch.ReadAll()
+		require.NoError(t, err)
+		ps[2] = internal.Product{Id: 2, ProductAttributes: internal.ProductAttributes{Expiration: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, IdWarehouse: 1, Version: 1}
+		err = ch.WriteAll(ps)
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(`[]`), 0644)
+		require.NoError(t, err)
+
+		// act
+		errFlush := ch.Flush()
+		ps, errRead := ch.ReadAll()
+		errWrite := ch.WriteAll(ps)
+		errTearDown := ch.Flush()
+
+		// assert
+		require.ErrorIs(t, errFlush, internal.ErrStoreProductModified)
+		require.NoError(t, errRead)
+		require.Contains(t, ps, 2)
+		require.ErrorIs(t, errWrite, internal.ErrStoreProductModified)
+		require.ErrorIs(t, errTearDown, internal.ErrStoreProductModified)
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, `[]`, string(b))
+	})
+
+	t.Run("error - scheduled flush not retried and the conflict reported on the next write", func(t *testing.T) {
+		// arrange
+		st, path := newStoreProductJSON(t)
+		ch := store.NewStoreProductCache(st, 10*time.Millisecond)
+		ps, err := ch.ReadAll()
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(`[]`), 0644)
+		require.NoError(t, err)
+		ps[2] = internal.Product{Id: 2, ProductAttributes: internal.ProductAttributes{Expiration: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, IdWarehouse: 1, Version: 1}
+		err = ch.WriteAll(ps)
+		require.NoError(t, err)
+
+		// act
+		var errWrite error
+		require.Eventually(t, func() bool {
+			errWrite = ch.WriteAll(ps)
+			return errWrite != nil
+		}, time.Second, 5*time.Millisecond)
+		ps, errRead := ch.ReadAll()
+
+		// assert
+		require.ErrorIs(t, errWrite, internal.ErrStoreProductModified)
+		require.NoError(t, errRead)
+		require.Contains(t, ps, 2)
+		require.ErrorIs(t, ch.Flush(), internal.ErrStoreProductModified)
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, `[]`, string(b))
+	})
+}
+
+// Tests for StoreProductCache when a scheduled flush fails
+func TestStoreProductCache_FlushFailed(t *testing.T) {
+	t.Run("error - next write written through and undone", func(t *testing.T) {
+		// arrange
+		st := &storeProductCounter{p: map[int]internal.Product{}, err: errors.New("disk full")}
+		ch := store.NewStoreProductCache(st, 10*time.Millisecond)
+		// the failed flushes are retried until stopped by Flush
+		t.Cleanup(func() { ch.Flush() })
+		err := ch.WriteAll(map[int]internal.Product{1: {Id: 1}})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			// the same write, written through once the scheduled flush failed
+			return ch.WriteAll(map[int]internal.Product{1: {Id: 1}}) != nil
+		}, time.Second, 5*time.Millisecond)
+
+		// act
+		err = ch.WriteAll(map[int]internal.Product{1: {Id: 1}, 2: {Id: 2}})
+		ps, errRead := ch.ReadAll()
+
+		// assert
+		require.Error(t, err)
+		require.NoError(t, errRead)
+		require.Len(t, ps, 1)
+	})
+}
@@ -2,8 +2,17 @@ package store
 
 import (
 	"app/internal"
+	"cmp"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,9 +25,21 @@ func NewStoreProductJSON(path string) (s *StoreProductJSON) {
 }
 
 // StoreProductJSON is a JSON file store for products.
+// Reads and writes are serialized. A write replaces the file atomically, keeps the previous
+// content as a backup and fails when the file was modified by someone else since the base of the store.
+//
+// The base is the content of the last write, or of the first read before any write. Reads do not move it,
+// otherwise a read of a file modified by someone else would let a write based on an earlier read overwrite
+// the modification. A failed write clears the base, so the next read takes the modified content as the new one.
 type StoreProductJSON struct {
 	// Path is the path to the JSON file.
 	Path string
+	// mu serializes the access to the file.
+	mu sync.Mutex
+	// state is the state of the file at the base of the store, nil before the first read or after a failed write.
+	state *fileState
+	// muSeq guards the id sequence.
+	muSeq sync.Mutex
 }
 
 // ProductJSON is a JSON representation of a product.
@@ -31,20 +52,30 @@ type ProductJSON struct {
 	Expiration  string  `json:"expiration"`
 	Price       float64 `json:"price"`
 	IdWarehouse int     `json:"id_warehouse"`
+	Version     int     `json:"version,omitempty"`
 }
 
 // ReadAll reads all products from the store.
 func (s *StoreProductJSON) ReadAll() (p map[int]internal.Product, err error) {
-	// open file
-	f, err := os.Open(s.Path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// read file
+	b, st, err := readFile(s.Path)
 	if err != nil {
 		return
 	}
-	defer f.Close()
+	if !st.exists {
+		err = &fs.PathError{Op: "open", Path: s.Path, Err: fs.ErrNotExist}
+		return
+	}
+	if s.state == nil {
+		s.state = &st
+	}
 
 	// decode JSON
 	var pr []ProductJSON
-	err = json.NewDecoder(f).Decode(&pr)
+	err = json.Unmarshal(b, &pr)
 	if err != nil {
 		return
 	}
@@ -58,6 +89,11 @@ func (s *StoreProductJSON) ReadAll() (p map[int]internal.Product, err error) {
 			return
 		}
 
+		// products written before versions existed start at version 1
+		if v.Version == 0 {
+			v.Version = 1
+		}
+
 		p[v.Id] = internal.Product{
 			Id: v.Id,
 			ProductAttributes: internal.ProductAttributes{
@@ -68,16 +104,23 @@ func (s *StoreProductJSON) ReadAll() (p map[int]internal.Product, err error) {
 				Expiration:  exp,
 				Price:       v.Price,
 			},
+			IdWarehouse: v.IdWarehouse,
+			Version:     v.Version,
 		}
 	}
 
 	return
 }
 
+// backupPath returns the path of the backup of the JSON file, its content before the last write.
+func (s *StoreProductJSON) backupPath() string {
+	return s.Path + ".bak"
+}
+
 // WriteAll writes all products to the store.
 func (s *StoreProductJSON) WriteAll(p map[int]internal.Product) (err error) {
 	// serialize
-	var pr []ProductJSON
+	pr := make([]ProductJSON, 0, len(p))
 	for _, v := range p {
 		pr = append(pr, ProductJSON{
 			Id:          v.Id,
@@ -88,22 +131,93 @@ func (s *StoreProductJSON) WriteAll(p map[int]internal.Product) (err error) {
 			Expiration:  v.Expiration.Format(time.DateOnly),
 			Price:       v.Price,
 			IdWarehouse: v.IdWarehouse,
+			Version:     v.Version,
 		})
 	}
+	slices.SortFunc(pr, func(a, b ProductJSON) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
 
-	// open file
-	// - create if not exists / write only / truncate
-	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	// encode JSON
+	b, err := json.Marshal(pr)
 	if err != nil {
 		return
 	}
-	defer f.Close()
+	b = append(b, '\n')
 
-	// encode JSON
-	err = json.NewEncoder(f).Encode(pr)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// check the file was not modified by someone else
+	current, st, err := readFile(s.Path)
 	if err != nil {
 		return
 	}
+	if s.state != nil && st.changedFrom(*s.state) {
+		s.state = nil
+		err = fmt.Errorf("%w: %s", internal.ErrStoreProductModified, s.Path)
+		return
+	}
+
+	// backup
+	if st.exists {
+		err = writeFileAtomic(s.backupPath(), current)
+		if err != nil {
+			return
+		}
+	}
+
+	// write file
+	err = writeFileAtomic(s.Path, b)
+	if err != nil {
+		return
+	}
+
+	// state
+	s.state = &fileState{exists: true, hash: sha256.Sum256(b)}
+
+	return
+}
+
+// sequencePath returns the path of the file with the last id assigned, next to the JSON file.
+func (s *StoreProductJSON) sequencePath() string {
+	return s.Path + ".seq"
+}
+
+// NextId assigns the next product id.
+// The last id assigned is persisted next to the JSON file, so ids are not reused even after
+// their product is deleted. Without that file the sequence starts after the greatest id of the store.
+func (s *StoreProductJSON) NextId() (id int, err error) {
+	s.muSeq.Lock()
+	defer s.muSeq.Unlock()
+
+	// last id
+	b, err := os.ReadFile(s.sequencePath())
+	switch {
+	case err == nil:
+		id, err = strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			return 0, fmt.Errorf("invalid sequence file %s: %w", s.sequencePath(), err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		var p map[int]internal.Product
+		p, err = s.ReadAll()
+		if err != nil {
+			return 0, err
+		}
+		for k := range p {
+			id = max(id, k)
+		}
+	default:
+		return 0, err
+	}
+
+	// next id
+	id++
+	err = writeFileAtomic(s.sequencePath(), []byte(strconv.Itoa(id)+"\n"))
+	if err != nil {
+		return 0, err
+	}
 
 	return
 }
@@ -0,0 +1,137 @@
+package store_test
+
+import (
+	"app/internal"
+	"app/internal/store"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStoreProductJSON returns a store on a temporary file with a product
+func newStoreProductJSON(t *testing.T) (st *store.StoreProductJSON, path string) {
+	path = filepath.Join(t.TempDir(), "products.json")
+	err := os.WriteFile(path, []byte(`[{"id":1,"name":"Corn Shoots","quantity":244,"code_value":"0009-1111","is_published":false,"expiration":"2022-01-08","price":23.27,"id_warehouse":1}]`), 0644)
+	require.NoError(t, err)
+	return store.NewStoreProductJSON(path), path
+}
+
+// Tests for StoreProductJSON.WriteAll
+func TestStoreProductJSON_WriteAll(t *testing.T) {
+	t.Run("success - previous content kept as backup", func(t *testing.T) {
+		// arrange
+		st, path := newStoreProductJSON(t)
+		before, err := os.ReadFile(path)
+		require.NoError(t, err)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+
+		// act
+		ps[2] = internal.Product{Id: 2, ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Expiration: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, IdWarehouse: 1, Version: 1}
+		err = st.WriteAll(ps)
+
+		// assert
+		require.NoError(t, err)
+		backup, err := os.ReadFile(path + ".bak")
+		require.NoError(t, err)
+		require.Equal(t, before, backup)
+		ps, err = st.ReadAll()
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+		files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp-*"))
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("error - file modified externally", func(t *testing.T) {
+		// arrange
+		st, path := newStoreProductJSON(t)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(`[]`), 0644)
+		require.NoError(t, err)
+
+		// act
+		err = st.WriteAll(ps)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrStoreProductModified)
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, `[]`, string(b))
+	})
+
+	t.Run("error - file modified externally before another read", func(t *testing.T) {
+		// arrange
+		st, path := newStoreProductJSON(t)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(`[]`), 0644)
+		require.NoError(t, err)
+		_, err = st.ReadAll()
+		require.NoError(t, err)
+
+		// act
+		err = st.WriteAll(ps)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrStoreProductModified)
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, `[]`, string(b))
+	})
+
+	t.Run("success - written again after reading the modified file", func(t *testing.T) {
+		// arrange
+		st, path := newStoreProductJSON(t)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(`[]`), 0644)
+		require.NoError(t, err)
+		err = st.WriteAll(ps)
+		require.ErrorIs(t, err, internal.ErrStoreProductModified)
+
+		// act
+		ps, err = st.ReadAll()
+		require.NoError(t, err)
+		ps[2] = internal.Product{Id: 2, ProductAttributes: internal.ProductAttributes{Name: "Persimmons", Expiration: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, IdWarehouse: 1, Version: 1}
+		err = st.WriteAll(ps)
+
+		// assert
+		require.NoError(t, err)
+		ps, err = st.ReadAll()
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+	})
+
+	t.Run("success - concurrent reads and id allocations", func(t *testing.T) {
+		// arrange
+		st, _ := newStoreProductJSON(t)
+
+		// act
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, err := st.ReadAll()
+				require.NoError(t, err)
+			}()
+			go func() {
+				defer wg.Done()
+				_, err := st.NextId()
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		// assert
+		id, err := st.NextId()
+		require.NoError(t, err)
+		require.Equal(t, 22, id)
+	})
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"app/internal"
+	"cmp"
+	"encoding/json"
+	"io/fs"
+	"slices"
+	"sync"
+	"time"
+)
+
+// NewStoreTransferJSON creates a new JSON file store for transfers.
+func NewStoreTransferJSON(path string) (s *StoreTransferJSON) {
+	s = &StoreTransferJSON{
+		Path: path,
+	}
+	return
+}
+
+// StoreTransferJSON is a JSON file store for transfers.
+// Reads and writes are serialized, and a write replaces the file atomically.
+type StoreTransferJSON struct {
+	// Path is the path to the JSON file.
+	Path string
+	// mu serializes the access to the file.
+	mu sync.Mutex
+}
+
+// TransferJSON is a JSON representation of a transfer.
+type TransferJSON struct {
+	Id            int    `json:"id"`
+	CodeValue     string `json:"code_value"`
+	Quantity      int    `json:"quantity"`
+	FromWarehouse int    `json:"from_warehouse"`
+	ToWarehouse   int    `json:"to_warehouse"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// ReadAll reads all transfers from the store.
+func (s *StoreTransferJSON) ReadAll() (t map[int]internal.Transfer, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// read file
+	b, st, err := readFile(s.Path)
+	if err != nil {
+		return
+	}
+	if !st.exists {
+		err = &fs.PathError{Op: "open", Path: s.Path, Err: fs.ErrNotExist}
+		return
+	}
+
+	// decode JSON
+	var tr []TransferJSON
+	err = json.Unmarshal(b, &tr)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	t = make(map[int]internal.Transfer)
+	for _, v := range tr {
+		var createdAt time.Time
+		createdAt, err = time.Parse(time.DateTime, v.CreatedAt)
+		if err != nil {
+			return
+		}
+
+		t[v.Id] = internal.Transfer{
+			Id:            v.Id,
+			CodeValue:     v.CodeValue,
+			Quantity:      v.Quantity,
+			FromWarehouse: v.FromWarehouse,
+			ToWarehouse:   v.ToWarehouse,
+			CreatedAt:     createdAt,
+		}
+	}
+
+	return
+}
+
+// WriteAll writes all transfers to the store.
+func (s *StoreTransferJSON) WriteAll(t map[int]internal.Transfer) (err error) {
+	// serialize
+	tr := make([]TransferJSON, 0, len(t))
+	for _, v := range t {
+		tr = append(tr, TransferJSON{
+			Id:            v.Id,
+			CodeValue:     v.CodeValue,
+			Quantity:      v.Quantity,
+			FromWarehouse: v.FromWarehouse,
+			ToWarehouse:   v.ToWarehouse,
+			CreatedAt:     v.CreatedAt.UTC().Format(time.DateTime),
+		})
+	}
+	slices.SortFunc(tr, func(a, b TransferJSON) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	// encode JSON
+	b, err := json.Marshal(tr)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// write file
+	err = writeFileAtomic(s.Path, b)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"app/internal"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NewStoreWarehouseJSON creates a new JSON file store for warehouses.
+func NewStoreWarehouseJSON(path string) (s *StoreWarehouseJSON) {
+	s = &StoreWarehouseJSON{
+		Path: path,
+	}
+	return
+}
+
+// StoreWarehouseJSON is a JSON file store for warehouses.
+// Reads and writes are serialized, and a write replaces the file atomically.
+type StoreWarehouseJSON struct {
+	// Path is the path to the JSON file.
+	Path string
+	// mu serializes the access to the file.
+	mu sync.Mutex
+	// muSeq guards the id sequence.
+	muSeq sync.Mutex
+}
+
+// WarehouseJSON is a JSON representation of a warehouse.
+type WarehouseJSON struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Telephone string `json:"telephone"`
+	Capacity  int    `json:"capacity"`
+}
+
+// ReadAll reads all warehouses from the store.
+func (s *StoreWarehouseJSON) ReadAll() (w map[int]internal.Warehouse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// read file
+	b, st, err := readFile(s.Path)
+	if err != nil {
+		return
+	}
+	if !st.exists {
+		err = &fs.PathError{Op: "open", Path: s.Path, Err: fs.ErrNotExist}
+		return
+	}
+
+	// decode JSON
+	var wr []WarehouseJSON
+	err = json.Unmarshal(b, &wr)
+	if err != nil {
+		return
+	}
+
+	// serialize
+	w = make(map[int]internal.Warehouse)
+	for _, v := range wr {
+		w[v.Id] = internal.Warehouse{
+			Id:        v.Id,
+			Name:      v.Name,
+			Address:   v.Address,
+			Telephone: v.Telephone,
+			Capacity:  v.Capacity,
+		}
+	}
+
+	return
+}
+
+// WriteAll writes all warehouses to the store.
+func (s *StoreWarehouseJSON) WriteAll(w map[int]internal.Warehouse) (err error) {
+	// serialize
+	wr := make([]WarehouseJSON, 0, len(w))
+	for _, v := range w {
+		wr = append(wr, WarehouseJSON{
+			Id:        v.Id,
+			Name:      v.Name,
+			Address:   v.Address,
+			Telephone: v.Telephone,
+			Capacity:  v.Capacity,
+		})
+	}
+	slices.SortFunc(wr, func(a, b WarehouseJSON) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	// encode JSON
+	b, err := json.Marshal(wr)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// write file
+	err = writeFileAtomic(s.Path, b)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// sequencePath returns the path of the file with the last id assigned, next to the JSON file.
+func (s *StoreWarehouseJSON) sequencePath() string {
+	return s.Path + ".seq"
+}
+
+// NextId assigns the next warehouse id.
+// The last id assigned is persisted next to the JSON file, so the id of a deleted warehouse is not
+// given to a new one the transfers would mistake for it. Without that file the sequence starts after
+// the greatest id of the store.
+func (s *StoreWarehouseJSON) NextId() (id int, err error) {
+	s.muSeq.Lock()
+	defer s.muSeq.Unlock()
+
+	// last id
+	b, err := os.ReadFile(s.sequencePath())
+	switch {
+	case err == nil:
+		id, err = strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			return 0, fmt.Errorf("invalid sequence file %s: %w", s.sequencePath(), err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		var w map[int]internal.Warehouse
+		w, err = s.ReadAll()
+		if err != nil {
+			return 0, err
+		}
+		for k := range w {
+			id = max(id, k)
+		}
+	default:
+		return 0, err
+	}
+
+	// next id
+	id++
+	err = writeFileAtomic(s.sequencePath(), []byte(strconv.Itoa(id)+"\n"))
+	if err != nil {
+		return 0, err
+	}
+
+	return
+}
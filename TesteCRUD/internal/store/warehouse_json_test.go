@@ -0,0 +1,34 @@
+package store_test
+
+import (
+	"app/internal"
+	"app/internal/store"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for StoreWarehouseJSON.NextId
+func TestStoreWarehouseJSON_NextId(t *testing.T) {
+	t.Run("success - id of a deleted warehouse not reused", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "warehouses.json")
+		err := os.WriteFile(path, []byte(`[{"id":1,"name":"Main Warehouse","capacity":100},{"id":2,"name":"Second Warehouse","capacity":50}]`), 0644)
+		require.NoError(t, err)
+		st := store.NewStoreWarehouseJSON(path)
+
+		// act
+		id1, err1 := st.NextId()
+		errWrite := st.WriteAll(map[int]internal.Warehouse{1: {Id: 1, Name: "Main Warehouse", Capacity: 100}})
+		id2, err2 := store.NewStoreWarehouseJSON(path).NextId()
+
+		// assert
+		require.NoError(t, err1)
+		require.Equal(t, 3, id1)
+		require.NoError(t, errWrite)
+		require.NoError(t, err2)
+		require.Equal(t, 4, id2)
+	})
+}
@@ -0,0 +1,19 @@
+package internal
+
+import "time"
+
+// Transfer is a struct that contains a movement of stock of a product between warehouses
+type Transfer struct {
+	// Id is the unique identifier of the transfer
+	Id int
+	// CodeValue is the code value of the product moved
+	CodeValue string
+	// Quantity is the quantity moved
+	Quantity int
+	// FromWarehouse is the unique identifier of the source warehouse
+	FromWarehouse int
+	// ToWarehouse is the unique identifier of the destination warehouse
+	ToWarehouse int
+	// CreatedAt is when the transfer was made
+	CreatedAt time.Time
+}
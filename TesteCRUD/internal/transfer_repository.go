@@ -0,0 +1,22 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrRepositoryTransferProductNotFound is returned when the source warehouse has no product with the code value.
+	ErrRepositoryTransferProductNotFound = errors.New("repository: product not found in the source warehouse")
+	// ErrRepositoryTransferQuantityInvalid is returned when the quantity is not positive or above the stock of the source.
+	ErrRepositoryTransferQuantityInvalid = errors.New("repository: invalid transfer quantity")
+	// ErrRepositoryTransferDestinationInvalid is returned when the destination warehouse does not exist or is the source.
+	ErrRepositoryTransferDestinationInvalid = errors.New("repository: invalid destination warehouse")
+)
+
+// RepositoryTransfer is an interface that contains the methods for a transfer repository
+type RepositoryTransfer interface {
+	// Save moves the quantity of the product from the source to the destination warehouse and records the transfer.
+	// The source row is split when part of its stock is moved, and merged into the destination row with the
+	// same code value if there is one. The destination must have room for the quantity.
+	Save(t *Transfer) (err error)
+	// FindByWarehouse returns the transfers from or to a warehouse, newest first
+	FindByWarehouse(id int) (t []Transfer, err error)
+}
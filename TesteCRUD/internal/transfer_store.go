@@ -0,0 +1,9 @@
+package internal
+
+// StoreTransfer is an interface for a transfer store.
+type StoreTransfer interface {
+	// ReadAll reads all transfers from the store.
+	ReadAll() (t map[int]Transfer, err error)
+	// WriteAll writes all transfers to the store.
+	WriteAll(t map[int]Transfer) (err error)
+}
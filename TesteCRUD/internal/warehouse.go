@@ -7,3 +7,25 @@ type Warehouse struct {
 	Telephone string
 	Capacity  int
 }
+
+// WarehouseReport is a struct that contains the stock summary of a warehouse
+type WarehouseReport struct {
+	// Warehouse is the warehouse
+	Warehouse
+	// Products is the number of products
+	Products int
+	// Units is the sum of the quantities of the products
+	Units int
+	// StockValue is the sum of quantity * price of the products
+	StockValue float64
+	// Utilization is the percentage of the capacity taken by the units
+	Utilization float64
+	// Expired is the number of products expired before the day of the report
+	Expired int
+	// ExpiringSoon is the number of products expiring from the day of the report up to the soon date
+	ExpiringSoon int
+	// Published is the number of published products
+	Published int
+	// Unpublished is the number of unpublished products
+	Unpublished int
+}
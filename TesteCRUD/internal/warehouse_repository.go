@@ -1,13 +1,34 @@
 package internal
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrRepositoryWarehouseNotFound = errors.New("repository: warehouse not found")
+	// ErrRepositoryWarehouseCapacityTooLow is returned when the capacity of a warehouse is below the quantity of its products.
+	ErrRepositoryWarehouseCapacityTooLow = errors.New("repository: capacity below the quantity of products")
+	// ErrRepositoryWarehouseInUse is returned when a warehouse to be deleted still has products.
+	ErrRepositoryWarehouseInUse = errors.New("repository: warehouse has products")
+	// ErrRepositoryWarehouseReassignInvalid is returned when the products cannot be moved to the given warehouse.
+	ErrRepositoryWarehouseReassignInvalid = errors.New("repository: invalid warehouse to reassign products")
 )
 
 type RepositoryWarehouse interface {
 	FindAll() ([]Warehouse, error)
 	FindById(id int) (w Warehouse, err error)
 	Save(w *Warehouse) (err error)
+	// Update updates a warehouse
+	// The capacity cannot be lower than the quantity of the products of the warehouse.
+	Update(w *Warehouse) (err error)
+	// Delete deletes a warehouse
+	// If the warehouse has products, reassignTo must be another warehouse with room for them,
+	// and they are moved there in the same transaction.
+	Delete(id int, reassignTo *int) (err error)
+	// Report returns the stock report of a warehouse
+	// Products expiring before today are expired, and up to soon (inclusive) are expiring soon.
+	Report(id int, today, soon time.Time) (r WarehouseReport, err error)
+	// ReportAll returns the stock report of every warehouse
+	ReportAll(today, soon time.Time) (r []WarehouseReport, err error)
 }
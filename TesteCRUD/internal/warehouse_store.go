@@ -0,0 +1,11 @@
+package internal
+
+// StoreWarehouse is an interface for a warehouse store.
+type StoreWarehouse interface {
+	// ReadAll reads all warehouses from the store.
+	ReadAll() (w map[int]Warehouse, err error)
+	// WriteAll writes all warehouses to the store.
+	WriteAll(w map[int]Warehouse) (err error)
+	// NextId assigns the next warehouse id, concurrent calls never get the same id.
+	NextId() (id int, err error)
+}
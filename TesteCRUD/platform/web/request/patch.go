@@ -0,0 +1,387 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentTypeMergePatch is the content type of a JSON merge patch (RFC 7396).
+	ContentTypeMergePatch = "application/merge-patch+json"
+	// ContentTypeJSONPatch is the content type of a JSON patch (RFC 6902).
+	ContentTypeJSONPatch = "application/json-patch+json"
+)
+
+var (
+	// ErrRequestContentTypeNotPatch is used when the request content type is not a supported patch type.
+	ErrRequestContentTypeNotPatch = errors.New("request content type is not a patch")
+	// ErrRequestPatchInvalid is used when the request patch or the document to patch is invalid.
+	ErrRequestPatchInvalid = errors.New("request patch invalid")
+	// ErrRequestPatchPathNotFound is used when a path of the request patch is not in the document.
+	ErrRequestPatchPathNotFound = errors.New("request patch path not found")
+	// ErrRequestPatchTestFailed is used when a test operation of the request patch fails.
+	ErrRequestPatchTestFailed = errors.New("request patch test failed")
+)
+
+// PatchContentType returns the content type of the request if it is a supported patch type
+func PatchContentType(r *http.Request) (ct string, err error) {
+	ct, _, err = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (ct != ContentTypeMergePatch && ct != ContentTypeJSONPatch) {
+		err = ErrRequestContentTypeNotPatch
+		return
+	}
+	return
+}
+
+// Patch applies the patch of the request body to the json document doc, following the request content type
+func Patch(r *http.Request, doc []byte) (patched []byte, err error) {
+	// check content type
+	ct, err := PatchContentType(r)
+	if err != nil {
+		return
+	}
+
+	// get body
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+
+	// apply patch
+	switch ct {
+	case ContentTypeMergePatch:
+		patched, err = MergePatch(doc, patch)
+	case ContentTypeJSONPatch:
+		patched, err = JSONPatch(doc, patch)
+	}
+	return
+}
+
+// MergePatch applies the JSON merge patch (RFC 7396) to the json document doc
+func MergePatch(doc, patch []byte) (patched []byte, err error) {
+	var d, p any
+	if err = json.Unmarshal(doc, &d); err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+	if err = json.Unmarshal(patch, &p); err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+
+	return json.Marshal(mergePatch(d, p))
+}
+
+// mergePatch merges the patch into the target, a null member of the patch removes the member of the target
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
+
+// patchOperation is an operation of a JSON patch
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies the JSON patch (RFC 6902) to the json document doc.
+// The operations are applied in order and the patch is applied entirely or not at all.
+func JSONPatch(doc, patch []byte) (patched []byte, err error) {
+	var d any
+	if err = json.Unmarshal(doc, &d); err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+	var ops []patchOperation
+	if err = json.Unmarshal(patch, &ops); err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+
+	for ix, op := range ops {
+		d, err = applyOperation(d, op)
+		if err != nil {
+			err = fmt.Errorf("operation %d: %w", ix, err)
+			return
+		}
+	}
+
+	return json.Marshal(d)
+}
+
+// applyOperation applies a JSON patch operation to the document d
+func applyOperation(d any, op patchOperation) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w. missing path", ErrRequestPatchInvalid)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// value
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w. missing value", ErrRequestPatchInvalid)
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		}
+	}
+	// from
+	var from []string
+	switch op.Op {
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%w. missing from", ErrRequestPatchInvalid)
+		}
+		if from, err = parsePointer(*op.From); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return addValue(d, path, value)
+	case "remove":
+		d, _, err = removeValue(d, path)
+		return d, err
+	case "replace":
+		if _, err := getValue(d, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		d, _, err = removeValue(d, path)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(d, path, value)
+	case "move":
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, fmt.Errorf("%w. cannot move %s into itself", ErrRequestPatchInvalid, *op.From)
+		}
+		d, value, err = removeValue(d, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(d, path, value)
+	case "copy":
+		value, err = getValue(d, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(d, path, deepCopy(value))
+	case "test":
+		current, err := getValue(d, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("%w: %s", ErrRequestPatchTestFailed, *op.Path)
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("%w. unknown op %q", ErrRequestPatchInvalid, op.Op)
+	}
+}
+
+// parsePointer parses a JSON pointer (RFC 6901) into its reference tokens
+func parsePointer(p string) (tokens []string, err error) {
+	if p == "" {
+		return
+	}
+	if !strings.HasPrefix(p, "/") {
+		err = fmt.Errorf("%w. invalid path %q", ErrRequestPatchInvalid, p)
+		return
+	}
+
+	tokens = strings.Split(p[1:], "/")
+	for ix, t := range tokens {
+		tokens[ix] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return
+}
+
+// arrayIndex parses the token as an index of an array of length n, "-" is n when end is allowed
+func arrayIndex(token string, n int, end bool) (ix int, err error) {
+	if token == "-" && end {
+		return n, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+		return 0, fmt.Errorf("%w. invalid index %q", ErrRequestPatchInvalid, token)
+	}
+
+	ix, err = strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w. invalid index %q", ErrRequestPatchInvalid, token)
+	}
+	if ix > n || (ix == n && !end) {
+		return 0, fmt.Errorf("%w: index %d", ErrRequestPatchPathNotFound, ix)
+	}
+	return
+}
+
+// getValue returns the value at the path of the document d
+func getValue(d any, path []string) (any, error) {
+	for _, t := range path {
+		switch n := d.(type) {
+		case map[string]any:
+			v, ok := n[t]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrRequestPatchPathNotFound, t)
+			}
+			d = v
+		case []any:
+			ix, err := arrayIndex(t, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			d = n[ix]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrRequestPatchPathNotFound, t)
+		}
+	}
+	return d, nil
+}
+
+// updateParent calls leaf with the parent of the path in the document d and the last token of the path,
+// and returns the document with the parent replaced by the one leaf returns
+func updateParent(d any, path []string, leaf func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return leaf(d, path[0])
+	}
+
+	switch n := d.(type) {
+	case map[string]any:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrRequestPatchPathNotFound, path[0])
+		}
+		child, err := updateParent(child, path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = child
+		return n, nil
+	case []any:
+		ix, err := arrayIndex(path[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := updateParent(n[ix], path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		n[ix] = child
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrRequestPatchPathNotFound, path[0])
+	}
+}
+
+// addValue adds the value at the path of the document d, an empty path replaces the whole document
+func addValue(d any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(d, path, func(parent any, token string) (any, error) {
+		switch n := parent.(type) {
+		case map[string]any:
+			n[token] = value
+			return n, nil
+		case []any:
+			ix, err := arrayIndex(token, len(n), true)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(n, ix, value), nil
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrRequestPatchPathNotFound, token)
+		}
+	})
+}
+
+// removeValue removes the value at the path of the document d and returns it
+func removeValue(d any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w. cannot remove the whole document", ErrRequestPatchInvalid)
+	}
+
+	var removed any
+	d, err := updateParent(d, path, func(parent any, token string) (any, error) {
+		switch n := parent.(type) {
+		case map[string]any:
+			v, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrRequestPatchPathNotFound, token)
+			}
+			removed = v
+			delete(n, token)
+			return n, nil
+		case []any:
+			ix, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = n[ix]
+			return slices.Delete(n, ix, ix+1), nil
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrRequestPatchPathNotFound, token)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, removed, nil
+}
+
+// deepCopy copies a decoded json value
+func deepCopy(v any) any {
+	switch n := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(n))
+		for k, e := range n {
+			c[k] = deepCopy(e)
+		}
+		return c
+	case []any:
+		c := make([]any, len(n))
+		for ix, e := range n {
+			c[ix] = deepCopy(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
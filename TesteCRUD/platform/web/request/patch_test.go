@@ -0,0 +1,123 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for MergePatch function
+func TestRequestMergePatch(t *testing.T) {
+	t.Run("success - replace, add and remove members", func(t *testing.T) {
+		// arrange
+		doc := []byte(`{"name":"Corn","price":10.5,"tags":{"a":1,"b":2}}`)
+		patch := []byte(`{"price":12,"quantity":3,"name":null,"tags":{"a":null,"c":3}}`)
+
+		// act
+		patched, err := request.MergePatch(doc, patch)
+
+		// assert
+		require.NoError(t, err)
+		require.JSONEq(t, `{"price":12,"quantity":3,"tags":{"b":2,"c":3}}`, string(patched))
+	})
+
+	t.Run("error - invalid patch", func(t *testing.T) {
+		// act
+		_, err := request.MergePatch([]byte(`{}`), []byte(`{"name":`))
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestPatchInvalid)
+	})
+}
+
+// Tests for JSONPatch function
+func TestRequestJSONPatch(t *testing.T) {
+	t.Run("success - operations applied in order", func(t *testing.T) {
+		// arrange
+		doc := []byte(`{"name":"Corn","price":10.5,"list":[1,2],"a~b":{"c/d":true}}`)
+		patch := []byte(`[
+			{"op":"test","path":"/name","value":"Corn"},
+			{"op":"replace","path":"/price","value":12},
+			{"op":"add","path":"/list/1","value":5},
+			{"op":"add","path":"/list/-","value":9},
+			{"op":"remove","path":"/list/0"},
+			{"op":"copy","from":"/name","path":"/code"},
+			{"op":"move","from":"/a~0b/c~1d","path":"/flag"}
+		]`)
+
+		// act
+		patched, err := request.JSONPatch(doc, patch)
+
+		// assert
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"Corn","code":"Corn","price":12,"list":[5,2,9],"a~b":{},"flag":true}`, string(patched))
+	})
+
+	t.Run("error - test failed", func(t *testing.T) {
+		// act
+		_, err := request.JSONPatch([]byte(`{"price":10}`), []byte(`[{"op":"test","path":"/price","value":11}]`))
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestPatchTestFailed)
+	})
+
+	t.Run("error - path not found", func(t *testing.T) {
+		// act
+		_, err := request.JSONPatch([]byte(`{"price":10}`), []byte(`[{"op":"replace","path":"/name","value":"Corn"}]`))
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestPatchPathNotFound)
+	})
+
+	t.Run("error - unknown op", func(t *testing.T) {
+		// act
+		_, err := request.JSONPatch([]byte(`{}`), []byte(`[{"op":"merge","path":"/name"}]`))
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestPatchInvalid)
+	})
+
+	t.Run("error - missing value", func(t *testing.T) {
+		// act
+		_, err := request.JSONPatch([]byte(`{}`), []byte(`[{"op":"add","path":"/name"}]`))
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestPatchInvalid)
+	})
+}
+
+// Tests for Patch function
+func TestRequestPatch(t *testing.T) {
+	t.Run("success - merge patch content type", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/merge-patch+json; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader(`{"name":"Pear"}`)),
+		}
+
+		// act
+		patched, err := request.Patch(&inputRequest, []byte(`{"name":"Corn"}`))
+
+		// assert
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"Pear"}`, string(patched))
+	})
+
+	t.Run("error - content-type", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{"name":"Pear"}`)),
+		}
+
+		// act
+		_, err := request.Patch(&inputRequest, []byte(`{"name":"Corn"}`))
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestContentTypeNotPatch)
+	})
+}